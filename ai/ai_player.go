@@ -1,8 +1,11 @@
 package ai
 
 import (
+	"context"
+	"fmt"
 	//"log"
 	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/kitsunemikan/six-purrpurrs/game"
@@ -51,9 +54,10 @@ var defaultMetricBasis = []RankMetric{
 	{1, 1},
 }
 
-// BoardRank is represented by a pair of player metric vectors in the default metric basis
+// BoardRank is represented by player metric vectors in the default metric basis,
+// one for each player indexed by its PlayerID
 type BoardRank struct {
-	P1, P2 playerMetrics
+	Players []playerMetrics
 }
 
 type moveOutcome struct {
@@ -61,13 +65,23 @@ type moveOutcome struct {
 	Rank BoardRank
 }
 
-func computeRank(s *game.GameState) BoardRank {
+// transposition is a position reached during the search. The same stones
+// may be placed in different orders, and then the search below them is
+// the same, since the player to move follows from the number of stones
+type transposition struct {
+	Hash  uint64
+	Depth int
+}
+
+func computeRank(strikes []game.Strike, playerCount int) BoardRank {
 	rank := BoardRank{
-		P1: newPlayerMetrics(defaultMetricBasis),
-		P2: newPlayerMetrics(defaultMetricBasis),
+		Players: make([]playerMetrics, playerCount),
+	}
+
+	for i := range rank.Players {
+		rank.Players[i] = newPlayerMetrics(defaultMetricBasis)
 	}
 
-	strikes := s.StrikeStat.Strikes()
 	for _, strike := range strikes {
 		var metric RankMetric
 		metric.Len = strike.Len
@@ -80,16 +94,11 @@ func computeRank(s *game.GameState) BoardRank {
 			metric.Extensions++
 		}
 
-		switch strike.Player {
-		case game.P1:
-			rank.P1.Add(metric, 1)
-
-		case game.P2:
-			rank.P2.Add(metric, 1)
-
-		default:
+		if !strike.Player.IsValid(len(rank.Players)) {
 			panic("unknown player")
 		}
+
+		rank.Players[strike.Player].Add(metric, 1)
 	}
 
 	return rank
@@ -120,6 +129,16 @@ func (pm playerMetrics) Add(rank RankMetric, count int) {
 	}
 }
 
+func (pm playerMetrics) clone() playerMetrics {
+	count := make([]int, len(pm.count))
+	copy(count, pm.count)
+
+	return playerMetrics{
+		basis: pm.basis,
+		count: count,
+	}
+}
+
 func (a playerMetrics) subtract(b playerMetrics) {
 	if &a.basis[0] != &b.basis[0] {
 		panic("ai: subtract metric vectors: different bases")
@@ -154,18 +173,6 @@ func (a playerMetrics) lessThan(b playerMetrics) bool {
 // then it's a guaranteed victory, but if cannot, the other player blocks the strike and turns it into 1-side
 // extensible, robbing it of victory.
 func MetricTwoSideExtensible(player game.PlayerID, canMoveNext bool, old *BoardRank, candidate *BoardRank) bool {
-	oldUs := old.P1
-	oldThem := old.P2
-	candUs := candidate.P1
-	candThem := candidate.P2
-
-	if player == game.P2 {
-		oldUs = old.P2
-		oldThem = old.P1
-		candUs = candidate.P2
-		candThem = candidate.P1
-	}
-
 	// TODO: where check victoriousness? If AI can't look far into the future, it
 	// can use heuristics to recognize some board configurations that will lead to
 	// the opponents victory 100% if certain moves aren't made. For example,
@@ -173,45 +180,180 @@ func MetricTwoSideExtensible(player game.PlayerID, canMoveNext bool, old *BoardR
 
 	// NOTE: we ignore canMoveNext, as the AI performs well enough
 
-	oldUs.subtract(oldThem)
-	candUs.subtract(candThem)
+	oldUs := relativeMetrics(old, player)
+	candUs := relativeMetrics(candidate, player)
 
 	return oldUs.lessThan(candUs)
 }
 
+// relativeMetrics returns the metrics of the player with metrics of all of its
+// opponents subtracted. The rank itself is not modified
+func relativeMetrics(rank *BoardRank, player game.PlayerID) playerMetrics {
+	us := rank.Players[player].clone()
+
+	for p := range rank.Players {
+		if game.PlayerID(p) == player {
+			continue
+		}
+
+		us.subtract(rank.Players[p])
+	}
+
+	return us
+}
+
 type AIPlayer struct {
-	id   game.PlayerID
 	rand *rand.Rand
 	cmp  IsRankBetterForPlayerFunc
 
 	recdepth int
 
+	// transpositions are the outcomes of the positions searched during the current move
+	transpositions map[transposition]moveOutcome
+
 	SearchDepth int
 
-	gameCopy *game.GameState
+	// searching is held during a move, since a cancelled move
+	// may still be unwinding, when the next one starts
+	searching sync.Mutex
+	gameCopy  *game.GameState
+
+	// The game and its events, that are yet to be applied to the game copy.
+	// Without a started game, the copy is made anew for each move
+	followed  sync.Mutex
+	following bool
+	start     game.View
+	events    []game.GameEvent
 }
 
-func NewDefaultAIPlayer(id game.PlayerID) *AIPlayer {
+func NewDefaultAIPlayer() *AIPlayer {
 	return &AIPlayer{
-		id:   id,
 		rand: rand.New(rand.NewSource(time.Now().Unix())),
 		cmp:  MetricTwoSideExtensible,
 	}
 }
 
-func (p *AIPlayer) minimax(state *game.GameState, player game.PlayerID, depth int) (BoardRank, Offset) {
-	outcomes := make([]moveOutcome, 0, len(state.Board.UnoccupiedCells()))
-	for move := range state.Board.UnoccupiedCells() {
-		state.MarkCell(move, player)
+// searchRadius is the distance from the marked cells within which moves are considered
+const searchRadius = 2
+
+// candidateMoves returns the cells the AI should consider. On expanding boards
+// these are all the unoccupied cells, since the game copy reveals only the cells
+// within the search radius. Bounded boards are available as a whole, so
+// the cells around the marked ones are collected explicitly.
+// The moves are copied, since the board changes while they're searched
+func candidateMoves(state *game.GameState) []Offset {
+	if !state.Board.Bounded() {
+		return append([]Offset(nil), state.Board.UnoccupiedCellList()...)
+	}
+
+	var candidates []Offset
+	seen := make(map[Offset]struct{})
+	for _, move := range state.MoveHistoryCopy() {
+		for dx := -searchRadius; dx <= searchRadius; dx++ {
+			for dy := -searchRadius; dy <= searchRadius; dy++ {
+				cell := state.Board.Normalize(move.Cell.AddXY(dx, dy))
+				if _, ok := seen[cell]; ok || state.Cell(cell) != game.CellUnoccupied {
+					continue
+				}
+
+				seen[cell] = struct{}{}
+				candidates = append(candidates, cell)
+			}
+		}
+	}
+
+	// Any first move is as good as the others, so let's start in the center,
+	// unless the layout has made it blocked or unavailable
+	if center := state.BoardBound().Center(); state.MoveNumber() == 1 && state.Cell(center) == game.CellUnoccupied {
+		candidates = append(candidates, center)
+	}
+
+	// The cells around the marked ones may run out before the board is full,
+	// and only the unoccupied cells are listed, so the layout is respected
+	if len(candidates) == 0 {
+		return append([]Offset(nil), state.Board.UnoccupiedCellList()...)
+	}
+
+	return candidates
+}
+
+// symmetryPruneStones is the number of stones up to which symmetric moves are pruned.
+// Later on positions are rarely symmetric, so it isn't worth the time
+const symmetryPruneStones = 8
+
+// distinctMoves leaves one move of each group of moves leading to the positions
+// that are the same up to translation and symmetries
+func distinctMoves(state *game.GameState, player game.PlayerID, candidates []Offset) []Offset {
+	distinct := make([]Offset, 0, len(candidates))
+	seen := make(map[uint64]struct{}, len(candidates))
+	for _, move := range candidates {
+		if state.CheckMove(move, player) != nil {
+			continue
+		}
+
+		if state.MarkCell(move, player) != nil {
+			continue
+		}
+
+		hash := state.Board.CanonicalHash()
+		state.UndoLastMove()
+
+		if _, symmetric := seen[hash]; symmetric {
+			continue
+		}
+
+		seen[hash] = struct{}{}
+		distinct = append(distinct, move)
+	}
+
+	return distinct
+}
+
+// minimax returns the best move for the player. The search stops
+// once the context is done, then the state is left as it was
+func (p *AIPlayer) minimax(ctx context.Context, state *game.GameState, player game.PlayerID, depth int) (BoardRank, Offset, error) {
+	position := transposition{state.Board.Hash(), depth}
+	if outcome, searched := p.transpositions[position]; searched {
+		return outcome.Rank, outcome.Cell, nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return BoardRank{}, Offset{}, err
+	}
+
+	candidates := candidateMoves(state)
+	if depth == p.SearchDepth {
+		if state.MoveNumber() <= symmetryPruneStones {
+			candidates = distinctMoves(state, player, candidates)
+		}
+
+		// The first of the equally good moves is chosen, so they're shuffled
+		// for the AI not to play the same games over and over
+		p.rand.Shuffle(len(candidates), func(i, j int) {
+			candidates[i], candidates[j] = candidates[j], candidates[i]
+		})
+	}
+
+	outcomes := make([]moveOutcome, 0, len(candidates))
+	for _, move := range candidates {
+		if state.CheckMove(move, player) != nil || state.MarkCell(move, player) != nil {
+			continue
+		}
 
 		p.recdepth++
 
 		var rank BoardRank
-		if depth == 1 {
-			// Calculate rank with move
-			rank = computeRank(state)
+		if depth == 1 || state.Over() {
+			// Calculate rank with move. A finished game, e.g., one that has reached
+			// its move limit, isn't searched further, since nobody moves after it
+			rank = computeRank(state.StrikeStat.Strikes(), state.PlayerCount())
 		} else {
-			rank, _ = p.minimax(state, player.Other(), depth-1)
+			var err error
+			rank, _, err = p.minimax(ctx, state, state.PlayerToMove(), depth-1)
+			if err != nil {
+				state.UndoLastMove()
+				return BoardRank{}, Offset{}, err
+			}
 		}
 
 		outcomes = append(outcomes, moveOutcome{move, rank})
@@ -219,6 +361,13 @@ func (p *AIPlayer) minimax(state *game.GameState, player game.PlayerID, depth in
 		state.UndoLastMove()
 	}
 
+	// Every cell is forbidden for the player, nothing to choose from
+	if len(outcomes) == 0 {
+		rank := computeRank(state.StrikeStat.Strikes(), state.PlayerCount())
+		p.transpositions[position] = moveOutcome{Offset{}, rank}
+		return rank, Offset{}, nil
+	}
+
 	// CanMoveNext tells us whether our current player can make a move
 	canMoveNext := depth%2 != 0
 
@@ -229,41 +378,164 @@ func (p *AIPlayer) minimax(state *game.GameState, player game.PlayerID, depth in
 		}
 	}
 
-	return bestOutcome.Rank, bestOutcome.Cell
+	p.transpositions[position] = bestOutcome
+	return bestOutcome.Rank, bestOutcome.Cell, nil
 }
 
-func (p *AIPlayer) MakeMove(g *game.GameState) Offset {
-	if p.gameCopy == nil {
-		// NOTE: border radius of 2 is the smallest playeble width, hence by using it
-		// we can skip checking if AI moves are within or not the board.
-		// Additionally, this game copy with all of its unoccupied cells will represent
-		// all the cells that the AI should consider. By limiting it to 2, we greatly limit
-		// the size of the search space for the performance's sake.
-		// Alas, if the border radius is increased, the AI player will be able to play more
-		// optimally, althogh it's up for a debate whether it's a good idea, as the game
-		// may as well never end if players play optimally (mathematicians couldn't prove it)
-		p.gameCopy = game.NewGame(game.GameOptions{
-			Border:  2,
-			Victory: g.VictoryChecker().Clone(),
-		})
+func (p *AIPlayer) MakeMove(g game.View) Offset {
+	move, _ := p.MakeMoveContext(context.Background(), g)
+	return move
+}
+
+// MakeMoveContext searches for the best move, until the context is done
+func (p *AIPlayer) MakeMoveContext(ctx context.Context, g game.View) (Offset, error) {
+	p.searching.Lock()
+	defer p.searching.Unlock()
+
+	p.followed.Lock()
+	following, start, events := p.following, p.start, p.events
+	p.start, p.events = nil, nil
+	p.followed.Unlock()
+
+	if start != nil {
+		p.gameCopy = newGameCopy(start)
+	}
+
+	for _, event := range events {
+		if err := p.follow(event); err != nil {
+			// The copy went out of sync with the game, so it's made anew from the view
+			p.gameCopy = nil
+			break
+		}
 	}
 
-	history := g.MoveHistoryCopy()
-	for p.gameCopy.MoveNumber() < g.MoveNumber() {
-		move := history[p.gameCopy.MoveNumber()-1]
-		p.gameCopy.MarkCell(move.Cell, move.Player)
+	if !following || p.gameCopy == nil {
+		p.gameCopy = newGameCopy(g)
 	}
 
 	p.recdepth = 0
+	p.transpositions = make(map[transposition]moveOutcome)
 
-	// log.Printf("%v: level 1 cell count: %v", p.id, len(p.gameCopy.Board.UnoccupiedCells()))
+	// log.Printf("%v: level 1 cell count: %v", g.PlayerToMove(), len(p.gameCopy.Board.UnoccupiedCells()))
 
-	_, bestCell := p.minimax(p.gameCopy, p.id, p.SearchDepth)
+	// During opening protocols the player may place stones of any colour,
+	// so the colour is whatever the game says is to move
+	me := g.PlayerToMove()
+	_, bestCell, err := p.minimax(ctx, p.gameCopy, me, p.SearchDepth)
+	if err != nil {
+		return Offset{}, fmt.Errorf("ai: make move: %w", err)
+	}
 
-	// log.Printf("%v: chose move %v\n", p.id, bestCell)
-	// log.Printf("%v: rec depth  %v\n", p.id, p.recdepth)
+	// log.Printf("%v: chose move %v\n", me, bestCell)
+	// log.Printf("%v: rec depth  %v\n", me, p.recdepth)
 
 	// log.Println()
 
-	return bestCell
+	return bestCell, nil
+}
+
+// newGameCopy makes a copy of the game, where the AI searches for moves.
+// On expanding boards, the border is as small as allowed by the search radius,
+// since all the unoccupied cells of the copy are considered, see candidateMoves
+func newGameCopy(g game.View) *game.GameState {
+	// NOTE: border radius of 2 is the smallest playeble width, hence by using it
+	// we limit the size of the search space for the performance's sake.
+	// Alas, if the border radius is increased, the AI player will be able to play more
+	// optimally, althogh it's up for a debate whether it's a good idea, as the game
+	// may as well never end if players play optimally (mathematicians couldn't prove it)
+	border := searchRadius
+	if g.BorderWidth() < border {
+		border = g.BorderWidth()
+	}
+
+	var boardSize Offset
+	if g.Bounded() {
+		boardSize = g.BoardBound().Dimensions()
+	}
+
+	gameCopy := game.NewGame(game.GameOptions{
+		Border:      border,
+		Topology:    g.Topology(),
+		BoardSize:   boardSize,
+		Wrap:        g.Wraps(),
+		Layout:      g.Layout(),
+		PlayerCount: g.PlayerCount(),
+		Turns:       g.TurnPolicy(),
+		Rules:       g.Rules(),
+		Victory:     g.VictoryChecker(),
+
+		MoveLimit:          g.MoveLimit(),
+		DrawWithoutWindows: g.DrawWithoutWindows(),
+	})
+
+	for _, move := range g.MoveHistoryCopy() {
+		if err := gameCopy.MarkCell(move.Cell, move.Player); err != nil {
+			panic(fmt.Sprintf("ai: new game copy: %v", err))
+		}
+	}
+
+	return gameCopy
+}
+
+// StartGame makes the AI keep its game copy in sync with the game events
+func (p *AIPlayer) StartGame(g game.View) {
+	p.followed.Lock()
+	defer p.followed.Unlock()
+
+	p.following = true
+	p.start = g
+	p.events = nil
+}
+
+// FollowGame queues the event, since the game copy may be in use by the search
+func (p *AIPlayer) FollowGame(event game.GameEvent) {
+	p.followed.Lock()
+	defer p.followed.Unlock()
+
+	p.events = append(p.events, event)
+}
+
+// follow applies the event to the game copy. Game over events are skipped,
+// since the copy ends the same way, and adjudicated results don't matter to the search
+func (p *AIPlayer) follow(event game.GameEvent) error {
+	switch event := event.(type) {
+	case game.MoveMadeEvent:
+		return p.gameCopy.MarkCell(event.Move.Cell, event.Move.Player)
+	case game.MoveUndoneEvent:
+		return p.gameCopy.UndoLastMove()
+	case game.GameResetEvent:
+		p.gameCopy.Reset()
+	}
+
+	return nil
+}
+
+// DecideOpening chooses the colour whose strikes rank better
+// against the strikes of the opponent
+func (p *AIPlayer) DecideOpening(g game.View, me game.PlayerID, choices []game.OpeningChoice) game.OpeningChoice {
+	rank := computeRank(g.Strikes(), g.PlayerCount())
+
+	us := relativeMetrics(&rank, me)
+	them := relativeMetrics(&rank, me.NextPlayer(g.PlayerCount()))
+
+	if us.lessThan(them) {
+		for _, choice := range choices {
+			if choice == game.OpeningSwapColor {
+				return choice
+			}
+		}
+	}
+
+	return game.OpeningKeepColor
+}
+
+// RespondToDraw accepts a draw, when the strikes of the player
+// who offered it rank better
+func (p *AIPlayer) RespondToDraw(g game.View, me, offeredBy game.PlayerID) bool {
+	rank := computeRank(g.Strikes(), g.PlayerCount())
+
+	us := relativeMetrics(&rank, me)
+	them := relativeMetrics(&rank, offeredBy)
+
+	return us.lessThan(them)
 }
@@ -8,22 +8,34 @@ import (
 	"github.com/kitsunemikan/six-purrpurrs/geom"
 )
 
+// ObstructivePlayer places stones next to the opponent's stones.
+// It plays for whichever player is to move, so it may place stones
+// of any colour during opening protocols
 type ObstructivePlayer struct {
-	Me   game.PlayerID
 	rand *rand.Rand
 }
 
-func NewObstructivePlayer(id game.PlayerID) game.PlayerAgent {
+func NewObstructivePlayer() game.PlayerAgent {
 	source := rand.NewSource(time.Now().UnixMicro())
 	return &ObstructivePlayer{
-		Me:   id,
 		rand: rand.New(source),
 	}
 }
 
-func (p *ObstructivePlayer) MakeMove(g *game.GameState) geom.Offset {
+func (p *ObstructivePlayer) canMoveAt(g game.View, cell geom.Offset) bool {
+	if g.Cell(cell) != game.CellUnoccupied {
+		return false
+	}
+
+	return g.CheckMove(cell, g.PlayerToMove()) == nil
+}
+
+func (p *ObstructivePlayer) MakeMove(g game.View) geom.Offset {
+	me := g.PlayerToMove()
+
 	// Collect shifts
-	dirs := make([]int, len(game.StrikeDirs))
+	strikeDirs := g.StrikeDirs()
+	dirs := make([]int, len(strikeDirs))
 	for i := range dirs {
 		dirs[i] = i
 	}
@@ -33,24 +45,43 @@ func (p *ObstructivePlayer) MakeMove(g *game.GameState) geom.Offset {
 		dirs[0], dirs[swapID] = dirs[swapID], dirs[0]
 	}
 
-	for opponentCell := range g.Board.PlayerCells()[p.Me.Other()] {
-		for i := 0; i < len(dirs); i++ {
-			cell := opponentCell.Add(game.StrikeDirs[dirs[i]].Offset())
-			if _, ok := g.Board.UnoccupiedCells()[cell]; ok {
-				return cell
-			}
+	for opponent := game.P1; int(opponent) < g.PlayerCount(); opponent++ {
+		if opponent == me {
+			continue
+		}
 
-			cell = opponentCell.Sub(game.StrikeDirs[dirs[i]].Offset())
-			if _, ok := g.Board.UnoccupiedCells()[cell]; ok {
-				return cell
+		for opponentCell := range g.PlayerCells(opponent) {
+			for i := 0; i < len(dirs); i++ {
+				cell := opponentCell.Add(strikeDirs[dirs[i]].Offset())
+				if p.canMoveAt(g, cell) {
+					return cell
+				}
+
+				cell = opponentCell.Sub(strikeDirs[dirs[i]].Offset())
+				if p.canMoveAt(g, cell) {
+					return cell
+				}
 			}
 		}
 	}
 
 	// If all opponent's cells are obstructed, choose unoccupied at random
-	for cell := range g.Board.UnoccupiedCells() {
-		return cell
+	for cell := range g.UnoccupiedCells() {
+		if g.CheckMove(cell, me) == nil {
+			return cell
+		}
 	}
 
 	panic("obstructing player: no unoccupied cells were present at all!")
 }
+
+// DecideOpening always swaps colours to get in the way of the opponent
+func (p *ObstructivePlayer) DecideOpening(g game.View, me game.PlayerID, choices []game.OpeningChoice) game.OpeningChoice {
+	for _, choice := range choices {
+		if choice == game.OpeningSwapColor {
+			return choice
+		}
+	}
+
+	return game.OpeningKeepColor
+}
@@ -1,6 +1,8 @@
 package ai
 
 import (
+	"math/rand"
+
 	"github.com/kitsunemikan/six-purrpurrs/game"
 	. "github.com/kitsunemikan/six-purrpurrs/geom"
 )
@@ -11,10 +13,18 @@ func NewRandomPlayer() game.PlayerAgent {
 	return &RandomPlayer{}
 }
 
-func (p *RandomPlayer) MakeMove(g *game.GameState) Offset {
-	for cell := range g.Board.UnoccupiedCells() {
+func (p *RandomPlayer) MakeMove(g game.View) Offset {
+	for cell := range g.UnoccupiedCells() {
+		if g.CheckMove(cell, g.PlayerToMove()) != nil {
+			continue
+		}
+
 		return cell
 	}
 
 	panic("random player: no unoccupied cells were present at all!")
 }
+
+func (p *RandomPlayer) DecideOpening(g game.View, me game.PlayerID, choices []game.OpeningChoice) game.OpeningChoice {
+	return choices[rand.Intn(len(choices))]
+}
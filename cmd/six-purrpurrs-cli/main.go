@@ -4,6 +4,8 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
@@ -11,33 +13,34 @@ import (
 	"github.com/kitsunemikan/six-purrpurrs/game"
 	"github.com/kitsunemikan/six-purrpurrs/gamecli"
 	. "github.com/kitsunemikan/six-purrpurrs/geom"
+	"github.com/kitsunemikan/six-purrpurrs/notation"
 )
 
-func NewAIPlayer1(id game.PlayerID) game.PlayerAgent {
-	p := ai.NewDefaultAIPlayer(id)
+func NewAIPlayer1() game.PlayerAgent {
+	p := ai.NewDefaultAIPlayer()
 	p.SearchDepth = 2
 	return p
 }
 
-func NewAIPlayer2(id game.PlayerID) game.PlayerAgent {
-	p := ai.NewDefaultAIPlayer(id)
+func NewAIPlayer2() game.PlayerAgent {
+	p := ai.NewDefaultAIPlayer()
 	p.SearchDepth = 3
 	return p
 }
 
-func NewLocalPlayer(id game.PlayerID) game.PlayerAgent {
+func NewLocalPlayer() game.PlayerAgent {
 	return gamecli.NewLocalPlayer()
 }
 
-func NewRandomPlayer(id game.PlayerID) game.PlayerAgent {
+func NewRandomPlayer() game.PlayerAgent {
 	return ai.NewRandomPlayer()
 }
 
-func NewObstructivePlayer(id game.PlayerID) game.PlayerAgent {
-	return ai.NewObstructivePlayer(id)
+func NewObstructivePlayer() game.PlayerAgent {
+	return ai.NewObstructivePlayer()
 }
 
-var playerTypeGenerators = map[string]func(game.PlayerID) game.PlayerAgent{
+var playerTypeGenerators = map[string]func() game.PlayerAgent{
 	"local":       NewLocalPlayer,
 	"random":      NewRandomPlayer,
 	"ai1":         NewAIPlayer1,
@@ -45,64 +48,327 @@ var playerTypeGenerators = map[string]func(game.PlayerID) game.PlayerAgent{
 	"obstructive": NewObstructivePlayer,
 }
 
+var topologies = map[string]game.Topology{
+	game.SquareTopology.String(): game.SquareTopology,
+	game.HexTopology.String():    game.HexTopology,
+}
+
+var overlinePolicies = map[string]game.OverlinePolicy{
+	game.OverlineAllowed.String():    game.OverlineAllowed,
+	game.OverlineNotCounted.String(): game.OverlineNotCounted,
+	game.OverlineLoses.String():      game.OverlineLoses,
+}
+
+var moveRules = map[string]game.MoveRules{
+	"free":  nil,
+	"renju": game.RenjuRules{Restricted: game.P1},
+}
+
+var openingProtocols = map[string]game.OpeningProtocol{
+	game.NoOpening.String():    game.NoOpening,
+	game.PieRule.String():      game.PieRule,
+	game.SwapOpening.String():  game.SwapOpening,
+	game.Swap2Opening.String(): game.Swap2Opening,
+}
+
 var (
 	unavailableCellFlag = flag.String("unavailablecell", " ", "a character to denote a yet locked cell")
 	availableCellFlag   = flag.String("availablecell", ".", "a character to denote a cell available for a move")
-	p1CellFlag          = flag.String("p1avatar", "X", "a character to denote the first player on the board")
-	p2CellFlag          = flag.String("p2avatar", "O", "a character to denote the second player on the board")
-	p1TypeFlag          = flag.String("p1", "local", fmt.Sprintf("specifies logic for the first player (available: %s)", availablePlayerTypes()))
-	p2TypeFlag          = flag.String("p2", "random", fmt.Sprintf("specifies logic for the second player (available: %s)", availablePlayerTypes()))
+	blockedCellFlag     = flag.String("blockedcell", "#", "a character to denote an obstacle cell")
+	avatarsFlag         = flag.String("avatars", "X,O,A,V", "a comma-separated list of characters to denote players on the board in turn order")
+	playersFlag         = flag.String("players", "local,random", fmt.Sprintf("a comma-separated list specifying logic for each player in turn order, at least 2 (available: %s)", availableOptions(playerTypeGenerators)))
 	wFlag               = flag.Uint("w", 40, "screen width")
 	hFlag               = flag.Uint("h", 20, "screen height")
 	borderFlag          = flag.Uint("border", 7, "the width of a border around marked cells where players can make a move")
+	topologyFlag        = flag.String("topology", "square", fmt.Sprintf("the shape of the board cells (available: %s)", availableOptions(topologies)))
+	sizeFlag            = flag.String("size", "", "fixed board dimensions, e.g., 15x15, or empty for an infinite expanding board")
+	layoutFlag          = flag.String("layout", "", "a file with a starting board layout, where '.' is a cell, '#' is an obstacle and ' ' is a hole; the board size is taken from the layout")
+	wrapFlag            = flag.Bool("wrap", false, "glue the opposite edges of a fixed-size board together")
 	strikeFlag          = flag.Uint("strike", 6, "the number of marks in a row to win the game")
+	overlineFlag        = flag.String("overline", "allowed", fmt.Sprintf("how strikes longer than the victory length are treated (available: %s)", availableOptions(overlinePolicies)))
+	rulesFlag           = flag.String("rules", "free", fmt.Sprintf("restrictions on where players may move (available: %s)", availableOptions(moveRules)))
+	openingFlag         = flag.String("opening", "none", fmt.Sprintf("an opening protocol for two-player games (available: %s)", availableOptions(openingProtocols)))
+	moveLimitFlag       = flag.Uint("movelimit", 0, "the number of moves after which the game ends in a draw, or 0 for no limit")
+	windowDrawFlag      = flag.Bool("windowdraw", false, "end a game on a fixed-size board in a draw, once no player can complete a strike")
+	timeFlag            = flag.Duration("time", 0, "the main thinking time of each player, e.g., 5m, or 0 for untimed games")
+	incrementFlag       = flag.Duration("increment", 0, "the time added after each turn made within the main time")
+	periodsFlag         = flag.Uint("byoyomi", 0, "the number of byo-yomi periods players get after the main time runs out")
+	periodTimeFlag      = flag.Duration("byoyomitime", 30*time.Second, "the length of a byo-yomi period")
+	turnsFlag           = flag.String("turns", "single", fmt.Sprintf("the number of stones players place each turn (available: %s)", availableOptions(notation.Variants)))
+	saveFlag            = flag.String("save", "six-purrpurrs-save.json", "a file where the game is saved with the save key")
+	loadFlag            = flag.String("load", "", "a saved game file to resume; the game options, players and avatars are taken from the file")
+	positionFlag        = flag.String("position", "", "a starting position like '-1,-1:x1o/1x o 7 6', see the notation package; the border and strike length are taken from the position")
 	trackDepthFlag      = flag.Uint("trackDepth", 20, "The width of camera borders in % after which to follow player moves")
 )
 
-func availablePlayerTypes() (list string) {
-	typeID := 0
-	for name := range playerTypeGenerators {
+// availableOptions lists the names of the options for a flag
+func availableOptions[T any](options map[string]T) (list string) {
+	optionID := 0
+	for name := range options {
 		list += name
-		if typeID < len(playerTypeGenerators)-1 {
+		if optionID < len(options)-1 {
 			list += ", "
 		}
-		typeID++
+		optionID++
 	}
 
 	return
 }
 
+func loadLayout(path string) (*game.Layout, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return game.ParseLayout(f)
+}
+
+// gameOptionsFromFlags builds the options of a new game from the command line
+func gameOptionsFromFlags(playerCount int) (game.GameOptions, game.OpeningProtocol) {
+	turns, exists := notation.Variants[*turnsFlag]
+	if !exists {
+		fmt.Fprintf(os.Stderr, "error: invalid turn policy supplied: '%s'\nnote: available policies are: %s\n", *turnsFlag, availableOptions(notation.Variants))
+		os.Exit(1)
+	}
+
+	overline, exists := overlinePolicies[*overlineFlag]
+	if !exists {
+		fmt.Fprintf(os.Stderr, "error: invalid overline policy supplied: '%s'\nnote: available policies are: %s\n", *overlineFlag, availableOptions(overlinePolicies))
+		os.Exit(1)
+	}
+
+	rules, exists := moveRules[*rulesFlag]
+	if !exists {
+		fmt.Fprintf(os.Stderr, "error: invalid move rules supplied: '%s'\nnote: available rules are: %s\n", *rulesFlag, availableOptions(moveRules))
+		os.Exit(1)
+	}
+
+	opening, exists := openingProtocols[*openingFlag]
+	if !exists {
+		fmt.Fprintf(os.Stderr, "error: invalid opening protocol supplied: '%s'\nnote: available protocols are: %s\n", *openingFlag, availableOptions(openingProtocols))
+		os.Exit(1)
+	}
+
+	if opening != game.NoOpening && playerCount != 2 {
+		fmt.Fprintf(os.Stderr, "error: opening protocol '%s' requires 2 players, got %d\n", *openingFlag, playerCount)
+		os.Exit(1)
+	}
+
+	topology, exists := topologies[*topologyFlag]
+	if !exists {
+		fmt.Fprintf(os.Stderr, "error: invalid topology supplied: '%s'\nnote: available topologies are: %s\n", *topologyFlag, availableOptions(topologies))
+		os.Exit(1)
+	}
+
+	var boardSize Offset
+	if *sizeFlag != "" {
+		_, err := fmt.Sscanf(*sizeFlag, "%dx%d", &boardSize.X, &boardSize.Y)
+		if err != nil || boardSize.X <= 0 || boardSize.Y <= 0 {
+			fmt.Fprintf(os.Stderr, "error: invalid board size supplied: '%s'\nnote: expected positive dimensions like 15x15\n", *sizeFlag)
+			os.Exit(1)
+		}
+	}
+
+	var layout *game.Layout
+	if *layoutFlag != "" {
+		var err error
+		layout, err = loadLayout(*layoutFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: load layout: %v\n", err)
+			os.Exit(1)
+		}
+
+		if !boardSize.IsZero() && !boardSize.IsEqual(layout.Size) {
+			fmt.Fprintf(os.Stderr, "error: board size %dx%d doesn't match the layout size %dx%d\n", boardSize.X, boardSize.Y, layout.Size.X, layout.Size.Y)
+			os.Exit(1)
+		}
+
+		boardSize = layout.Size
+	}
+
+	if *wrapFlag && boardSize.IsZero() {
+		fmt.Fprintf(os.Stderr, "error: only fixed-size boards may wrap, see -size\n")
+		os.Exit(1)
+	}
+
+	var victory game.VictoryChecker = &game.EightDirStrikeVictoryChecker{
+		VictoryLength: int(*strikeFlag),
+	}
+
+	if overline != game.OverlineAllowed {
+		victory = &game.ExactStrikeVictoryChecker{
+			VictoryLength: int(*strikeFlag),
+			Overline:      overline,
+			PlayerCount:   playerCount,
+		}
+	}
+
+	gameConf := game.GameOptions{
+		Border:      int(*borderFlag),
+		Topology:    topology,
+		BoardSize:   boardSize,
+		Wrap:        *wrapFlag,
+		Layout:      layout,
+		PlayerCount: playerCount,
+		Turns:       turns,
+		Rules:       rules,
+		Victory:     victory,
+
+		MoveLimit:          int(*moveLimitFlag),
+		DrawWithoutWindows: *windowDrawFlag,
+	}
+
+	return gameConf, opening
+}
+
 func main() {
 	flag.Parse()
 
 	theme := gamecli.DefaultBoardTheme
 	theme.InvalidCell = *unavailableCellFlag
 	theme.UnoccupiedCell = *availableCellFlag
-	theme.PlayerCells = []string{*p1CellFlag, *p2CellFlag}
+	theme.BlockedCell = *blockedCellFlag
+	theme.PlayerCells = strings.Split(*avatarsFlag, ",")
+	playerTypes := strings.Split(*playersFlag, ",")
+
+	// The players and their avatars are taken from the saved game, when resuming it
+	var saved *gamecli.SavedGame
+	if *loadFlag != "" {
+		loaded, err := gamecli.ReadSavedGame(*loadFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if *positionFlag != "" {
+			fmt.Fprintf(os.Stderr, "error: a saved game can't start from a position\n")
+			os.Exit(1)
+		}
+
+		saved = &loaded
+		theme.PlayerCells = saved.Avatars
+		playerTypes = saved.PlayerTypes
+	}
 
 	// Create players
-	players := make([]game.PlayerAgent, 2)
-	if _, exists := playerTypeGenerators[*p1TypeFlag]; !exists {
-		fmt.Fprintf(os.Stderr, "error: invalid player type supplied: '%s'\nnote: available types are: %s\n", *p1TypeFlag, availablePlayerTypes())
+	if len(playerTypes) < 2 {
+		fmt.Fprintf(os.Stderr, "error: at least 2 players are required, got %d\n", len(playerTypes))
 		os.Exit(1)
 	}
 
-	if _, exists := playerTypeGenerators[*p2TypeFlag]; !exists {
-		fmt.Fprintf(os.Stderr, "error: invalid player type supplied: '%s'\nnote: available types are: %s\n", *p2TypeFlag, availablePlayerTypes())
+	if len(theme.PlayerCells) < len(playerTypes) {
+		fmt.Fprintf(os.Stderr, "error: not enough avatars for %d players: '%s'\n", len(playerTypes), strings.Join(theme.PlayerCells, ","))
 		os.Exit(1)
 	}
 
-	players[0] = playerTypeGenerators[*p1TypeFlag](game.P1)
-	players[1] = playerTypeGenerators[*p2TypeFlag](game.P2)
+	players := make([]game.PlayerAgent, len(playerTypes))
+	for i, playerType := range playerTypes {
+		if _, exists := playerTypeGenerators[playerType]; !exists {
+			fmt.Fprintf(os.Stderr, "error: invalid player type supplied: '%s'\nnote: available types are: %s\n", playerType, availableOptions(playerTypeGenerators))
+			os.Exit(1)
+		}
 
-	gameConf := game.GameOptions{
-		Border: int(*borderFlag),
-		Victory: &game.EightDirStrikeVictoryChecker{
-			VictoryLength: int(*strikeFlag),
-		},
+		players[i] = playerTypeGenerators[playerType]()
+	}
+
+	var gameState *game.GameState
+	var gameConf game.GameOptions
+	var opening *game.Opening
+	var setupStones int
+	if saved != nil {
+		var err error
+		gameState, opening, err = saved.Restore()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: load game: %v\n", err)
+			os.Exit(1)
+		}
+
+		if result := gameState.Result(); result.Over() {
+			fmt.Fprintf(os.Stderr, "error: load game: the game is over: %v\n", result)
+			os.Exit(1)
+		}
+
+		if gameState.PlayerCount() != len(players) {
+			fmt.Fprintf(os.Stderr, "error: load game: got %d player types for a game of %d players\n", len(players), gameState.PlayerCount())
+			os.Exit(1)
+		}
+
+		gameConf = saved.Options
+		setupStones = saved.SetupStones
+	} else {
+		var position *notation.Position
+		if *positionFlag != "" {
+			parsed, err := notation.ParsePosition(*positionFlag)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+
+			if *openingFlag != game.NoOpening.String() {
+				fmt.Fprintf(os.Stderr, "error: opening protocols start from an empty board, but a position is supplied\n")
+				os.Exit(1)
+			}
+
+			// The rest of the options still come from the flags
+			position = &parsed
+			*borderFlag = uint(position.Border)
+			*strikeFlag = uint(position.StrikeLength)
+		}
+
+		var protocol game.OpeningProtocol
+		gameConf, protocol = gameOptionsFromFlags(len(players))
+
+		gameState = game.NewGame(gameConf)
+		opening = game.NewOpening(protocol)
+
+		if position != nil {
+			if err := position.Place(gameState); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+
+			setupStones = len(position.Stones)
+		}
 	}
 
-	game := game.NewGame(gameConf)
+	save := func(g *game.GameState, opening *game.Opening) error {
+		saved := gamecli.NewSavedGame(gameConf, g, opening, playerTypes, theme.PlayerCells)
+
+		// Setup stones may have been taken back
+		saved.SetupStones = setupStones
+		if len(saved.Moves) < setupStones {
+			saved.SetupStones = len(saved.Moves)
+		}
+
+		return gamecli.WriteSavedGame(*saveFlag, saved)
+	}
+
+	var clock *game.Clock
+	if *timeFlag > 0 || *periodsFlag > 0 {
+		if len(players) != 2 {
+			fmt.Fprintf(os.Stderr, "error: clocks require 2 players, got %d\n", len(players))
+			os.Exit(1)
+		}
+
+		if *periodsFlag > 0 && *periodTimeFlag <= 0 {
+			fmt.Fprintf(os.Stderr, "error: byo-yomi periods require a positive period length, got %v\n", *periodTimeFlag)
+			os.Exit(1)
+		}
+
+		if *timeFlag < 0 || *incrementFlag < 0 {
+			fmt.Fprintf(os.Stderr, "error: negative time supplied\n")
+			os.Exit(1)
+		}
+
+		clock = game.NewClock(game.TimeControl{
+			MainTime:       *timeFlag,
+			Increment:      *incrementFlag,
+			ByoYomiPeriods: int(*periodsFlag),
+			ByoYomiTime:    *periodTimeFlag,
+		}, len(players))
+	}
 
 	w, h := int(*wFlag), int(*hFlag)
 	minDim := w
@@ -112,8 +378,11 @@ func main() {
 
 	trackDepth := int(*trackDepthFlag) * minDim / 100
 	modelConf := gamecli.GameplayModelConfig{
-		Game:       game,
+		Game:       gameState,
 		Players:    players,
+		Opening:    opening,
+		Clock:      clock,
+		Save:       save,
 		Theme:      &theme,
 		ScreenSize: Offset{X: w, Y: h},
 		TrackDepth: trackDepth,
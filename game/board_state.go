@@ -8,7 +8,7 @@ import (
 
 // We use the fact that the only deltas possible are:
 // 1. Unavailable -> Unoccupied
-// 2. Unoccupied -> P1 or P2
+// 2. Unoccupied -> any player
 //
 // Both of these can be reversed in one-to-one correspondance
 type cellDelta struct {
@@ -23,20 +23,38 @@ type boardDelta struct {
 }
 
 type BoardState struct {
-	board           map[Offset]CellState
-	unoccupiedCells map[Offset]struct{}
-	playerCells     [2]map[Offset]struct{}
+	// cells are stored densely in chunks, while the unoccupied cells and
+	// the cells of each player are also listed, to be iterated quickly.
+	// The cells know their index in the lists, see cellChunk
+	cells      cellGrid
+	unoccupied []Offset
+	stones     [][]Offset
 
 	// delta is assumed to be immutable as well as boardDelta values.
 	// So, Clone() will share delta values
 	delta       []boardDelta
 	moveHistory []PlayerMove
 
-	// Precalculated disk offsets, immutable
+	// Precalculated offsets of cells revealed around a move, immutable
 	circleMask []Offset
+	topology   Topology
+
+	// Buffers reused by each move, see MarkCell
+	revealChunks []*cellChunk
+	revealed     []cellDelta
 
 	borderWidth int
 	boardBound  Rect
+
+	// bounded boards have all of their cells available from the start,
+	// and never grow beyond the board bound
+	bounded bool
+
+	// wrapping boards are bounded boards with opposite edges glued together
+	wraps bool
+
+	// hash is the XOR of the Zobrist keys of the marked cells
+	hash uint64
 }
 
 func generateCircleMask(radius int) (mask []Offset) {
@@ -60,20 +78,24 @@ func generateCircleMask(radius int) (mask []Offset) {
 	return
 }
 
-func NewBoardState(borderWidth int) *BoardState {
+func NewBoardState(borderWidth, playerCount int) *BoardState {
+	return NewBoardStateWithTopology(SquareTopology, borderWidth, playerCount)
+}
+
+// NewBoardStateWithTopology creates an expanding board, where the cells
+// are revealed around each move according to the topology
+func NewBoardStateWithTopology(topology Topology, borderWidth, playerCount int) *BoardState {
 	bs := &BoardState{
-		board:           make(map[Offset]CellState),
-		unoccupiedCells: make(map[Offset]struct{}),
+		cells:  newCellGrid(),
+		stones: make([][]Offset, playerCount),
 
-		circleMask: generateCircleMask(borderWidth),
+		circleMask: topology.revealMask(borderWidth),
+		topology:   topology,
 
 		borderWidth: borderWidth,
 		boardBound:  Rect{X: -borderWidth, Y: -borderWidth, W: 2*borderWidth + 1, H: 2*borderWidth + 1},
 	}
 
-	bs.playerCells[0] = make(map[Offset]struct{})
-	bs.playerCells[1] = make(map[Offset]struct{})
-
 	// Mark initial available cells
 	for _, ds := range bs.circleMask {
 		bs.markUnoccupied(ds)
@@ -82,24 +104,81 @@ func NewBoardState(borderWidth int) *BoardState {
 	return bs
 }
 
+// NewBoundedBoardState creates a fixed-size board, where every cell
+// inside the bound is unoccupied from the start
+func NewBoundedBoardState(topology Topology, bound Rect, playerCount int) *BoardState {
+	if bound.W <= 0 || bound.H <= 0 {
+		panic(fmt.Sprintf("new bounded board state: bound must be non-empty (bound=%v)", bound))
+	}
+
+	bs := &BoardState{
+		cells:      newCellGrid(),
+		unoccupied: make([]Offset, 0, bound.Area()),
+		stones:     make([][]Offset, playerCount),
+
+		topology:   topology,
+		boardBound: bound,
+		bounded:    true,
+	}
+
+	for y := 0; y < bound.H; y++ {
+		for x := 0; x < bound.W; x++ {
+			bs.markUnoccupied(bound.ToWorldXY(x, y))
+		}
+	}
+
+	return bs
+}
+
+// NewWrappingBoardState creates a fixed-size board, where cells outside
+// of the bound wrap around onto the opposite side
+func NewWrappingBoardState(topology Topology, bound Rect, playerCount int) *BoardState {
+	bs := NewBoundedBoardState(topology, bound, playerCount)
+	bs.wraps = true
+
+	return bs
+}
+
+// applyLayout shapes a bounded board before any moves are made. The layout
+// is placed at the top-left corner of the board bound
+func (bs *BoardState) applyLayout(layout *Layout) {
+	if !bs.bounded || bs.MoveCount() != 0 {
+		panic("board state: apply layout: layouts may only be applied to empty bounded boards")
+	}
+
+	if !layout.Size.IsEqual(bs.boardBound.Dimensions()) {
+		panic(fmt.Sprintf("board state: apply layout: layout size %v doesn't match the board bound %v", layout.Size, bs.boardBound))
+	}
+
+	for y := 0; y < layout.Size.Y; y++ {
+		for x := 0; x < layout.Size.X; x++ {
+			cell := bs.boardBound.ToWorldXY(x, y)
+
+			switch state := layout.Cell(Offset{X: x, Y: y}); state {
+			case CellUnavailable, CellBlocked:
+				chunk, i := bs.cells.cellForChange(cell)
+				bs.unlist(&bs.unoccupied, chunk, i)
+				chunk.setState(i, state)
+			}
+		}
+	}
+}
+
 // NewBoardStateFromCells expects a non-zero border width
-func NewBoardStateFromCells(borderWidth int, cells map[Offset]CellState) *BoardState {
+func NewBoardStateFromCells(borderWidth, playerCount int, cells map[Offset]CellState) *BoardState {
 	bs := &BoardState{
-		board: make(map[Offset]CellState, len(cells)),
+		cells: newCellGrid(),
 		// Size's just a hint, I will trade performance for extra memory consumption
 		// Assuming for one player move there are ~borderWidth*borderWidth new cells
 		// It's basically almost the full len(cells)
-		unoccupiedCells: make(map[Offset]struct{}, len(cells)),
+		unoccupied: make([]Offset, 0, len(cells)),
+		stones:     make([][]Offset, playerCount),
 
 		circleMask: generateCircleMask(borderWidth),
 
 		borderWidth: borderWidth,
 	}
 
-	// Random "intuitive", but substantially smaller hint than full len(cells)
-	bs.playerCells[0] = make(map[Offset]struct{}, len(cells)/borderWidth)
-	bs.playerCells[1] = make(map[Offset]struct{}, len(cells)/borderWidth)
-
 	// A questionable... I guess... way to get any element from a map
 	minX, minY, maxX, maxY := 0, 0, 0, 0
 	for cell := range cells {
@@ -109,14 +188,17 @@ func NewBoardStateFromCells(borderWidth int, cells map[Offset]CellState) *BoardS
 	}
 
 	for cell, state := range cells {
-		bs.board[cell] = state
-		switch state {
-		case CellUnoccupied:
-			bs.unoccupiedCells[cell] = struct{}{}
-		case CellP1:
-			bs.playerCells[0][cell] = struct{}{}
-		case CellP2:
-			bs.playerCells[1][cell] = struct{}{}
+		chunk, i := bs.cells.cellForChange(cell)
+		switch {
+		case state == CellBlocked:
+			chunk.setState(i, state)
+		case state == CellUnoccupied:
+			bs.list(&bs.unoccupied, cell, chunk, i)
+			chunk.setState(i, state)
+		case state >= 0 && int(state) < playerCount:
+			bs.list(&bs.stones[state], cell, chunk, i)
+			chunk.setState(i, state)
+			bs.hash ^= zobristKey(cell, PlayerID(state))
 		default:
 			panic(fmt.Sprintf("new board state from cells: encountered an invalid cell at %v (state=%v)", cell, state))
 		}
@@ -141,41 +223,32 @@ func NewBoardStateFromCells(borderWidth int, cells map[Offset]CellState) *BoardS
 
 func (bs *BoardState) SetBorderWidth(newWidth int) {
 	bs.borderWidth = newWidth
-	bs.circleMask = generateCircleMask(newWidth)
+	bs.circleMask = bs.topology.revealMask(newWidth)
 }
 
 // TODO: do we need this?
 func (bs *BoardState) Clone() *BoardState {
 	newBs := &BoardState{
-		board:           make(map[Offset]CellState, len(bs.board)),
-		unoccupiedCells: make(map[Offset]struct{}, len(bs.unoccupiedCells)),
+		cells:      bs.cells.clone(),
+		unoccupied: make([]Offset, len(bs.unoccupied)),
+		stones:     make([][]Offset, len(bs.stones)),
 
 		delta:       make([]boardDelta, len(bs.delta)),
 		moveHistory: make([]PlayerMove, len(bs.moveHistory)),
 
 		circleMask: bs.circleMask,
+		topology:   bs.topology,
 
 		borderWidth: bs.borderWidth,
 		boardBound:  bs.boardBound,
+		bounded:     bs.bounded,
+		wraps:       bs.wraps,
+		hash:        bs.hash,
 	}
 
-	newBs.playerCells[0] = make(map[Offset]struct{}, len(bs.playerCells[0]))
-	newBs.playerCells[1] = make(map[Offset]struct{}, len(bs.playerCells[1]))
-
-	for k, v := range bs.board {
-		newBs.board[k] = v
-	}
-
-	for k, v := range bs.unoccupiedCells {
-		newBs.unoccupiedCells[k] = v
-	}
-
-	for k, v := range bs.playerCells[0] {
-		newBs.playerCells[0][k] = v
-	}
-
-	for k, v := range bs.playerCells[1] {
-		newBs.playerCells[1][k] = v
+	copy(newBs.unoccupied, bs.unoccupied)
+	for i := range bs.stones {
+		newBs.stones[i] = append([]Offset(nil), bs.stones[i]...)
 	}
 
 	copy(newBs.moveHistory, bs.moveHistory)
@@ -188,6 +261,12 @@ func (bs *BoardState) BorderWidth() int {
 	return bs.borderWidth
 }
 
+// Hash identifies the stones on the board regardless of the order they were
+// placed in. Unoccupied and blocked cells don't contribute to the hash
+func (bs *BoardState) Hash() uint64 {
+	return bs.hash
+}
+
 func (bs *BoardState) Delta() []boardDelta {
 	return bs.delta
 }
@@ -200,17 +279,55 @@ func (bs *BoardState) MoveHistoryCopy() []PlayerMove {
 	return historyCopy
 }
 
-// Do not modify the result
+// AllCells returns the available cells in a new map. Use Cell
+// to look up cells, since the map is built on each call
 func (bs *BoardState) AllCells() map[Offset]CellState {
-	return bs.board
+	cells := make(map[Offset]CellState, len(bs.unoccupied)+len(bs.moveHistory))
+	bs.cells.forEach(func(pos Offset, state CellState) {
+		cells[pos] = state
+	})
+
+	return cells
 }
 
-func (bs *BoardState) PlayerCells() [2]map[Offset]struct{} {
-	return bs.playerCells
+// PlayerCells returns the cells of each player in new sets, see PlayerCellList
+func (bs *BoardState) PlayerCells() []map[Offset]struct{} {
+	playerCells := make([]map[Offset]struct{}, len(bs.stones))
+	for player := range bs.stones {
+		playerCells[player] = cellListToSet(bs.stones[player])
+	}
+
+	return playerCells
+}
+
+// PlayerCellList returns the cells of the player in no particular order,
+// which changes as the cells are marked. Do not modify the result
+func (bs *BoardState) PlayerCellList(player PlayerID) []Offset {
+	return bs.stones[player]
 }
 
+func (bs *BoardState) PlayerCount() int {
+	return len(bs.stones)
+}
+
+// UnoccupiedCells returns the unoccupied cells in a new set, see UnoccupiedCellList
 func (bs *BoardState) UnoccupiedCells() map[Offset]struct{} {
-	return bs.unoccupiedCells
+	return cellListToSet(bs.unoccupied)
+}
+
+// UnoccupiedCellList returns the unoccupied cells in no particular order,
+// which changes as the cells are marked. Do not modify the result
+func (bs *BoardState) UnoccupiedCellList() []Offset {
+	return bs.unoccupied
+}
+
+func cellListToSet(cells []Offset) map[Offset]struct{} {
+	set := make(map[Offset]struct{}, len(cells))
+	for _, cell := range cells {
+		set[cell] = struct{}{}
+	}
+
+	return set
 }
 
 func (bs *BoardState) MoveCount() int {
@@ -218,18 +335,43 @@ func (bs *BoardState) MoveCount() int {
 }
 
 func (bs *BoardState) Cell(pos Offset) CellState {
-	state, available := bs.board[pos]
-	if !available {
-		return CellUnavailable
-	}
-
-	return state
+	return bs.cells.state(bs.Normalize(pos))
 }
 
 func (bs *BoardState) BoardBound() Rect {
 	return bs.boardBound
 }
 
+func (bs *BoardState) Topology() Topology {
+	return bs.topology
+}
+
+// Bounded reports whether the board has a fixed size
+func (bs *BoardState) Bounded() bool {
+	return bs.bounded
+}
+
+// Wraps reports whether the opposite edges of the board are glued together
+func (bs *BoardState) Wraps() bool {
+	return bs.wraps
+}
+
+// Normalize maps cells outside of a wrapping board onto the board.
+// Other boards leave the cell as is
+func (bs *BoardState) Normalize(pos Offset) Offset {
+	if !bs.wraps {
+		return pos
+	}
+
+	return pos.WrapIntoRect(bs.boardBound)
+}
+
+// IsFull reports whether there are no unoccupied cells left. Only bounded
+// boards may ever fill up
+func (bs *BoardState) IsFull() bool {
+	return bs.bounded && len(bs.unoccupied) == 0
+}
+
 func (bs *BoardState) LatestMove() PlayerMove {
 	if len(bs.moveHistory) == 0 {
 		panic("game state: get last move: no moves have been yet made")
@@ -238,27 +380,65 @@ func (bs *BoardState) LatestMove() PlayerMove {
 	return bs.moveHistory[len(bs.moveHistory)-1]
 }
 
+// list appends the cell to the list, and remembers its index in the chunk
+func (bs *BoardState) list(cells *[]Offset, pos Offset, chunk *cellChunk, i int) {
+	chunk.listIndex[i] = int32(len(*cells))
+	*cells = append(*cells, pos)
+}
+
+// unlist removes the cell from the list, moving the last cell of the list in its place
+func (bs *BoardState) unlist(cells *[]Offset, chunk *cellChunk, i int) {
+	index := chunk.listIndex[i]
+
+	last := (*cells)[len(*cells)-1]
+	*cells = (*cells)[:len(*cells)-1]
+	if int(index) == len(*cells) {
+		return
+	}
+
+	(*cells)[index] = last
+	lastChunk, lastI := bs.cells.cellForChange(last)
+	lastChunk.listIndex[lastI] = index
+}
+
 // Will turn an unavailable cell into an unoccupied cell.
 // Panics if cell is already available.
 func (bs *BoardState) markUnoccupied(pos Offset) {
-	previousState, exists := bs.board[pos]
-	if exists {
+	chunk, i := bs.cells.cellForChange(pos)
+	if previousState := chunk.state(i); previousState != CellUnavailable {
 		panic(fmt.Sprintf("board state: add unoccupied cell at %v: the cell is already present (state=%d)", pos, previousState))
 	}
 
-	bs.board[pos] = CellUnoccupied
-	bs.unoccupiedCells[pos] = struct{}{}
+	chunk.setState(i, CellUnoccupied)
+	bs.list(&bs.unoccupied, pos, chunk, i)
 }
 
 func (bs *BoardState) MarkCell(pos Offset, player PlayerID) {
+	if !player.IsValid(len(bs.stones)) {
+		panic(fmt.Sprintf("board state: mark cell at %v: invalid player %v", pos, player))
+	}
+
+	pos = bs.Normalize(pos)
+
+	chunk, i := bs.cells.cellForChange(pos)
+
 	// XXX: is this okkkkk?
-	if state, ok := bs.board[pos]; ok && state != CellUnoccupied {
+	state := chunk.state(i)
+	if state != CellUnavailable && state != CellUnoccupied {
 		panic(fmt.Sprintf("Trying to mark an occupied cell at %#v", pos))
 	}
 
-	bs.board[pos] = CellState(player)
-	delete(bs.unoccupiedCells, pos)
-	bs.playerCells[player][pos] = struct{}{}
+	if state == CellUnavailable && bs.bounded {
+		panic(fmt.Sprintf("board state: mark cell at %v: the cell is outside of the bounded board (bound=%v)", pos, bs.boardBound))
+	}
+
+	if state == CellUnoccupied {
+		bs.unlist(&bs.unoccupied, chunk, i)
+	}
+
+	chunk.setState(i, CellState(player))
+	bs.list(&bs.stones[player], pos, chunk, i)
+	bs.hash ^= zobristKey(pos, player)
 
 	bs.moveHistory = append(bs.moveHistory, PlayerMove{pos, player})
 
@@ -266,6 +446,12 @@ func (bs *BoardState) MarkCell(pos Offset, player PlayerID) {
 	delta.Cells = append(delta.Cells, cellDelta{Cell: pos, NewState: CellState(player)})
 	delta.OldBoardBound = bs.boardBound
 
+	if bs.bounded {
+		delta.NewBoardBound = bs.boardBound
+		bs.delta = append(bs.delta, delta)
+		return
+	}
+
 	// Update board bounding rectangle
 	borderOffset := Offset{X: bs.borderWidth, Y: bs.borderWidth}
 	newCellsBoundingRect := NewRectFromOffsets(pos.Sub(borderOffset), borderOffset.ScaleUp(2).AddXY(1, 1))
@@ -273,17 +459,25 @@ func (bs *BoardState) MarkCell(pos Offset, player PlayerID) {
 	bs.boardBound = bs.boardBound.GrowToContainRect(newCellsBoundingRect)
 	delta.NewBoardBound = bs.boardBound
 
-	// Create new available cells
+	// Create new available cells. The cells around the move span a few chunks,
+	// which are looked up beforehand
+	area := bs.cells.areaForChange(newCellsBoundingRect, bs.revealChunks)
+	bs.revealChunks = area.chunks
+
+	bs.revealed = append(bs.revealed[:0], delta.Cells...)
 	for _, ds := range bs.circleMask {
 		curCell := pos.Add(ds)
 
-		_, available := bs.board[curCell]
-		if !available {
-			bs.markUnoccupied(curCell)
-			delta.Cells = append(delta.Cells, cellDelta{curCell, CellUnoccupied})
+		chunk, i := area.cellAt(curCell)
+		if chunk.state(i) == CellUnavailable {
+			chunk.setState(i, CellUnoccupied)
+			bs.list(&bs.unoccupied, curCell, chunk, i)
+			bs.revealed = append(bs.revealed, cellDelta{curCell, CellUnoccupied})
 		}
 	}
 
+	// Deltas are kept for the whole game, so they take no more space than needed
+	delta.Cells = append(make([]cellDelta, 0, len(bs.revealed)), bs.revealed...)
 	bs.delta = append(bs.delta, delta)
 }
 
@@ -300,15 +494,18 @@ func (bs *BoardState) UndoLastMove() {
 	bs.boardBound = lastDelta.OldBoardBound
 
 	for _, dcell := range lastDelta.Cells {
-		switch dcell.NewState {
-		case CellUnoccupied:
-			delete(bs.board, dcell.Cell)
-			delete(bs.unoccupiedCells, dcell.Cell)
-
-		case CellP1, CellP2:
-			bs.board[dcell.Cell] = CellUnoccupied
-			delete(bs.playerCells[dcell.NewState], dcell.Cell)
-			bs.unoccupiedCells[dcell.Cell] = struct{}{}
+		chunk, i := bs.cells.cellForChange(dcell.Cell)
+
+		switch {
+		case dcell.NewState == CellUnoccupied:
+			bs.unlist(&bs.unoccupied, chunk, i)
+			chunk.setState(i, CellUnavailable)
+
+		case int(dcell.NewState) >= 0 && int(dcell.NewState) < len(bs.stones):
+			bs.unlist(&bs.stones[dcell.NewState], chunk, i)
+			chunk.setState(i, CellUnoccupied)
+			bs.list(&bs.unoccupied, dcell.Cell, chunk, i)
+			bs.hash ^= zobristKey(dcell.Cell, PlayerID(dcell.NewState))
 
 		default:
 			panic(fmt.Sprintf("board state: undo last move: invalid cell delta at %v, new state=%v", dcell.Cell, dcell.NewState))
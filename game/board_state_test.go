@@ -1,26 +1,50 @@
 package game_test
 
 import (
+	"fmt"
+	"math/rand"
+	"sort"
 	"testing"
 	"testing/quick"
 
-	"github.com/kitsunemikan/six-purrpurrs/ai"
 	"github.com/kitsunemikan/six-purrpurrs/game"
 	"github.com/kitsunemikan/six-purrpurrs/game/gametest"
 	"github.com/kitsunemikan/six-purrpurrs/gamecli"
+	"github.com/kitsunemikan/six-purrpurrs/geom"
+	"github.com/maxatome/go-testdeep/td"
 	"github.com/sanity-io/litter"
 )
 
-func TestBoardStateRevertability(t *testing.T) {
-	randomPlayer := ai.NewRandomPlayer()
+func anyUnoccupiedCell(board *game.BoardState) geom.Offset {
+	for cell := range board.UnoccupiedCells() {
+		return cell
+	}
+
+	panic("any unoccupied cell: no unoccupied cells were present at all!")
+}
+
+// sortedCells orders the cells by rows, for random choices to be reproducible
+func sortedCells(set map[geom.Offset]struct{}) []geom.Offset {
+	cells := make([]geom.Offset, 0, len(set))
+	for cell := range set {
+		cells = append(cells, cell)
+	}
+
+	sort.Slice(cells, func(i, j int) bool {
+		return cells[i].Y < cells[j].Y || cells[i].Y == cells[j].Y && cells[i].X < cells[j].X
+	})
+
+	return cells
+}
 
+func TestBoardStateRevertability(t *testing.T) {
 	assertion := func(moveCount uint8) bool {
 		moveCount /= 4
 		if moveCount < 2 {
 			moveCount = 2
 		}
 
-		board := game.NewBoardState(3)
+		board := game.NewBoardState(3, 2)
 
 		boardHistory := make([]*game.BoardState, moveCount+1)
 
@@ -28,10 +52,10 @@ func TestBoardStateRevertability(t *testing.T) {
 		for i := 0; i < int(moveCount); i++ {
 			boardHistory[i] = board.Clone()
 
-			nextMove := randomPlayer.MakeMove(board)
+			nextMove := anyUnoccupiedCell(board)
 			board.MarkCell(nextMove, player)
 
-			player = player.Other()
+			player = player.NextPlayer(board.PlayerCount())
 		}
 
 		// To print parent board if the first undo fails
@@ -70,3 +94,153 @@ func TestBoardStateRevertability(t *testing.T) {
 		t.Errorf("#%d: failed with input %d", checkErr.Count, checkErr.In[0])
 	}
 }
+
+func TestBoundedBoardState(t *testing.T) {
+	bound := geom.Rect{X: -1, Y: -1, W: 3, H: 2}
+	board := game.NewBoundedBoardState(game.SquareTopology, bound, 2)
+
+	td.Cmp(t, len(board.UnoccupiedCells()), bound.Area())
+
+	initial := board.Clone()
+	board.MarkCell(geom.Offset{X: -1, Y: -1}, game.P1)
+	board.MarkCell(geom.Offset{X: 1, Y: 0}, game.P2)
+
+	td.Cmp(t, board.BoardBound(), bound)
+	td.Cmp(t, len(board.UnoccupiedCells()), bound.Area()-2)
+	td.Cmp(t, board.Cell(geom.Offset{X: 2, Y: 0}), game.CellUnavailable)
+
+	board.UndoLastMove()
+	board.UndoLastMove()
+
+	if err := gametest.BoardStatesEqual(board, initial); err != nil {
+		t.Errorf("undo didn't restore the initial board: %v", err)
+	}
+
+	td.CmpPanic(t, func() { board.MarkCell(geom.Offset{X: 2, Y: 0}, game.P1) }, td.Contains("outside of the bounded board"))
+}
+
+func TestBoundedBoardStateFull(t *testing.T) {
+	board := game.NewBoundedBoardState(game.SquareTopology, geom.Rect{W: 2, H: 1}, 2)
+
+	board.MarkCell(geom.Offset{X: 0, Y: 0}, game.P1)
+	td.CmpFalse(t, board.IsFull())
+
+	board.MarkCell(geom.Offset{X: 1, Y: 0}, game.P2)
+	td.CmpTrue(t, board.IsFull())
+
+	td.CmpFalse(t, game.NewBoardState(1, 2).IsFull())
+}
+
+func TestHexBoardStateRevealMask(t *testing.T) {
+	board := game.NewBoardStateWithTopology(game.HexTopology, 2, 2)
+
+	// A hexagon of radius 2 has 19 cells
+	td.Cmp(t, len(board.UnoccupiedCells()), 19)
+
+	for cell := range board.UnoccupiedCells() {
+		td.CmpTrue(t, cell.IsInsideHexagon(2), "cell %v", cell)
+	}
+}
+
+func TestBoardStateHash(t *testing.T) {
+	moves := []game.PlayerMove{
+		{Cell: geom.Offset{X: 0, Y: 0}, Player: game.P1},
+		{Cell: geom.Offset{X: 1, Y: 1}, Player: game.P2},
+		{Cell: geom.Offset{X: -2, Y: 1}, Player: game.P1},
+		{Cell: geom.Offset{X: 2, Y: -1}, Player: game.P2},
+	}
+
+	board := game.NewBoardState(3, 2)
+	emptyHash := board.Hash()
+
+	for _, move := range moves {
+		board.MarkCell(move.Cell, move.Player)
+	}
+
+	// The same stones in a different order
+	reordered := game.NewBoardState(3, 2)
+	for _, i := range []int{3, 0, 2, 1} {
+		reordered.MarkCell(moves[i].Cell, moves[i].Player)
+	}
+
+	td.Cmp(t, reordered.Hash(), board.Hash())
+	td.Cmp(t, board.Clone().Hash(), board.Hash())
+	td.Cmp(t, game.NewBoardStateFromCells(3, 2, board.AllCells()).Hash(), board.Hash())
+
+	// The same cells of other players
+	swapped := game.NewBoardState(3, 2)
+	for _, move := range moves {
+		swapped.MarkCell(move.Cell, move.Player.NextPlayer(2))
+	}
+
+	td.Cmp(t, swapped.Hash(), td.Not(board.Hash()))
+
+	board.UndoLastMove()
+	td.Cmp(t, board.Hash(), td.Not(reordered.Hash()))
+	td.Cmp(t, game.NewBoardStateFromCells(3, 2, board.AllCells()).Hash(), board.Hash())
+
+	for board.MoveCount() > 0 {
+		board.UndoLastMove()
+	}
+
+	td.Cmp(t, board.Hash(), emptyHash)
+}
+
+func TestBoardStateHashFromCells(t *testing.T) {
+	assertion := func(seed int64, moveCount uint8) bool {
+		board := game.NewBoardState(2, 3)
+
+		rng := rand.New(rand.NewSource(seed))
+		player := game.P1
+		for i := 0; i < int(moveCount%32); i++ {
+			cells := sortedCells(board.UnoccupiedCells())
+			board.MarkCell(cells[rng.Intn(len(cells))], player)
+			player = player.NextPlayer(board.PlayerCount())
+
+			// Take back every third move to mix undos in
+			if i%3 == 2 {
+				board.UndoLastMove()
+			}
+		}
+
+		fromCells := game.NewBoardStateFromCells(2, 3, board.AllCells())
+		if fromCells.Hash() != board.Hash() {
+			t.Logf("case failed: after %d moves: got hash %#x, from cells %#x", board.MoveCount(), board.Hash(), fromCells.Hash())
+			return false
+		}
+
+		return true
+	}
+
+	if err := quick.Check(assertion, nil); err != nil {
+		checkErr := err.(*quick.CheckError)
+		t.Errorf("#%d: failed with input %v", checkErr.Count, checkErr.In)
+	}
+}
+
+// BenchmarkBoardStateMarkUndo marks and takes back a stone on a board of 40 stones.
+// Storing the cells in chunks instead of maps took it from ~3200 to ~820 ns/op
+// with the border of 2, and from ~18000 to ~3300 ns/op with the border of 7
+func BenchmarkBoardStateMarkUndo(b *testing.B) {
+	for _, border := range []int{2, 7} {
+		b.Run(fmt.Sprintf("border %d", border), func(b *testing.B) {
+			board := game.NewBoardState(border, 2)
+
+			rng := rand.New(rand.NewSource(1))
+			player := game.P1
+			for i := 0; i < 40; i++ {
+				cells := sortedCells(board.UnoccupiedCells())
+				board.MarkCell(cells[rng.Intn(len(cells))], player)
+				player = player.NextPlayer(board.PlayerCount())
+			}
+
+			moves := sortedCells(board.UnoccupiedCells())
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				board.MarkCell(moves[i%len(moves)], player)
+				board.UndoLastMove()
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package game
+
+import (
+	"sort"
+
+	. "github.com/kitsunemikan/six-purrpurrs/geom"
+)
+
+// CanonicalForm is the representative of all the positions that are the same
+// up to translation and the symmetries of the topology. Equivalent positions
+// have equal canonical forms
+type CanonicalForm struct {
+	// Stones are sorted by rows, and the top-left corner of their bounding box is the origin
+	Stones []PlayerMove
+
+	// Transform maps the board cells onto the canonical ones
+	Transform Transform
+
+	// Hash is the Zobrist hash of the canonical stones
+	Hash uint64
+}
+
+// lessStones orders stones by rows, then by columns, and then by players
+func lessStones(a, b PlayerMove) bool {
+	if a.Cell.Y != b.Cell.Y {
+		return a.Cell.Y < b.Cell.Y
+	}
+
+	if a.Cell.X != b.Cell.X {
+		return a.Cell.X < b.Cell.X
+	}
+
+	return a.Player < b.Player
+}
+
+// transformStones applies the symmetry to the stones and moves
+// the top-left corner of their bounding box to the origin
+func transformStones(stones []PlayerMove, symmetry Symmetry) ([]PlayerMove, Transform) {
+	transformed := make([]PlayerMove, len(stones))
+	corner := Offset{}
+	for i, stone := range stones {
+		cell := symmetry.Apply(stone.Cell)
+		transformed[i] = PlayerMove{cell, stone.Player}
+
+		if i == 0 || cell.X < corner.X {
+			corner.X = cell.X
+		}
+
+		if i == 0 || cell.Y < corner.Y {
+			corner.Y = cell.Y
+		}
+	}
+
+	for i := range transformed {
+		transformed[i].Cell = transformed[i].Cell.Sub(corner)
+	}
+
+	sort.Slice(transformed, func(i, j int) bool {
+		return lessStones(transformed[i], transformed[j])
+	})
+
+	return transformed, Transform{Symmetry: symmetry, Shift: corner.ScaleUp(-1)}
+}
+
+// Canonicalize returns the canonical form of the stones on the board. Of all the
+// transformed stones, the one which is lexicographically the smallest is chosen.
+// The edges of bounded boards tell the positions apart, so such boards are
+// left as they are
+func Canonicalize(bs *BoardState) CanonicalForm {
+	stones := make([]PlayerMove, 0, len(bs.moveHistory))
+	for player, cells := range bs.stones {
+		for _, cell := range cells {
+			stones = append(stones, PlayerMove{cell, PlayerID(player)})
+		}
+	}
+
+	if bs.bounded {
+		sort.Slice(stones, func(i, j int) bool {
+			return lessStones(stones[i], stones[j])
+		})
+
+		return CanonicalForm{Stones: stones, Transform: Transform{Symmetry: Identity}, Hash: bs.hash}
+	}
+
+	var form CanonicalForm
+	for i, symmetry := range bs.topology.Symmetries() {
+		transformed, transform := transformStones(stones, symmetry)
+		if i == 0 || lessStoneLists(transformed, form.Stones) {
+			form.Stones = transformed
+			form.Transform = transform
+		}
+	}
+
+	for _, stone := range form.Stones {
+		form.Hash ^= zobristKey(stone.Cell, stone.Player)
+	}
+
+	return form
+}
+
+func lessStoneLists(a, b []PlayerMove) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return lessStones(a[i], b[i])
+		}
+	}
+
+	return false
+}
+
+// CanonicalHash returns the hash, which is the same for the positions that
+// differ only by translation and the symmetries of the topology
+func (bs *BoardState) CanonicalHash() uint64 {
+	return Canonicalize(bs).Hash
+}
@@ -0,0 +1,96 @@
+package game_test
+
+import (
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"github.com/kitsunemikan/six-purrpurrs/game"
+	"github.com/kitsunemikan/six-purrpurrs/geom"
+)
+
+func boardWithStones(topology game.Topology, stones []game.PlayerMove) *game.BoardState {
+	board := game.NewBoardStateWithTopology(topology, 3, 2)
+	for _, stone := range stones {
+		board.MarkCell(stone.Cell, stone.Player)
+	}
+
+	return board
+}
+
+func TestCanonicalize(t *testing.T) {
+	stones := []game.PlayerMove{
+		{Cell: geom.Offset{X: 0, Y: 0}, Player: game.P1},
+		{Cell: geom.Offset{X: 1, Y: 0}, Player: game.P2},
+		{Cell: geom.Offset{X: 1, Y: 2}, Player: game.P1},
+	}
+
+	board := boardWithStones(game.SquareTopology, stones)
+	form := game.Canonicalize(board)
+
+	for _, symmetry := range geom.Symmetries {
+		transform := geom.Transform{Symmetry: symmetry, Shift: geom.Offset{X: -4, Y: 9}}
+
+		transformed := make([]game.PlayerMove, len(stones))
+		for i, stone := range stones {
+			transformed[i] = game.PlayerMove{Cell: transform.Apply(stone.Cell), Player: stone.Player}
+		}
+
+		other := game.Canonicalize(boardWithStones(game.SquareTopology, transformed))
+		td.Cmp(t, other.Stones, form.Stones, "%v", symmetry)
+		td.Cmp(t, other.Hash, form.Hash, "%v", symmetry)
+
+		// The transform maps the board onto the canonical stones
+		for _, stone := range transformed {
+			td.Cmp(t, other.Stones, td.Contains(game.PlayerMove{Cell: other.Transform.Apply(stone.Cell), Player: stone.Player}), "%v", symmetry)
+		}
+	}
+
+	// The colours matter
+	swapped := boardWithStones(game.SquareTopology, []game.PlayerMove{
+		{Cell: geom.Offset{X: 0, Y: 0}, Player: game.P2},
+		{Cell: geom.Offset{X: 1, Y: 0}, Player: game.P1},
+		{Cell: geom.Offset{X: 1, Y: 2}, Player: game.P2},
+	})
+
+	td.Cmp(t, swapped.CanonicalHash(), td.Not(board.CanonicalHash()))
+
+	// The shape matters too
+	board.UndoLastMove()
+	board.MarkCell(geom.Offset{X: 2, Y: 2}, game.P1)
+	td.Cmp(t, board.CanonicalHash(), td.Not(form.Hash))
+}
+
+func TestCanonicalizeHex(t *testing.T) {
+	stones := []game.PlayerMove{
+		{Cell: geom.Offset{X: 0, Y: 0}, Player: game.P1},
+		{Cell: geom.Offset{X: 1, Y: 0}, Player: game.P1},
+		{Cell: geom.Offset{X: 0, Y: 1}, Player: game.P2},
+	}
+
+	rotated := make([]game.PlayerMove, len(stones))
+	turned := make([]game.PlayerMove, len(stones))
+	for i, stone := range stones {
+		rotated[i] = game.PlayerMove{Cell: geom.Rotate180.Apply(stone.Cell), Player: stone.Player}
+		turned[i] = game.PlayerMove{Cell: geom.Rotate90.Apply(stone.Cell), Player: stone.Player}
+	}
+
+	hash := boardWithStones(game.HexTopology, stones).CanonicalHash()
+	td.Cmp(t, boardWithStones(game.HexTopology, rotated).CanonicalHash(), hash)
+
+	// A square rotation doesn't keep hex cells adjacent, so the position differs
+	td.Cmp(t, boardWithStones(game.HexTopology, turned).CanonicalHash(), td.Not(hash))
+}
+
+func TestCanonicalizeBounded(t *testing.T) {
+	bound := geom.Rect{X: 0, Y: 0, W: 5, H: 5}
+
+	board := game.NewBoundedBoardState(game.SquareTopology, bound, 2)
+	board.MarkCell(geom.Offset{X: 0, Y: 0}, game.P1)
+
+	shifted := game.NewBoundedBoardState(game.SquareTopology, bound, 2)
+	shifted.MarkCell(geom.Offset{X: 2, Y: 2}, game.P1)
+
+	td.Cmp(t, board.CanonicalHash(), board.Hash())
+	td.Cmp(t, shifted.CanonicalHash(), td.Not(board.CanonicalHash()))
+}
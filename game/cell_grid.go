@@ -0,0 +1,145 @@
+package game
+
+import (
+	. "github.com/kitsunemikan/six-purrpurrs/geom"
+)
+
+// Cells are stored in square chunks of chunkWidth x chunkWidth cells
+const (
+	chunkShift = 4
+	chunkWidth = 1 << chunkShift
+	chunkMask  = chunkWidth - 1
+)
+
+// cellChunk is a square of cells. The zero value is a chunk of unavailable cells
+type cellChunk struct {
+	// states are stored relative to CellUnavailable, so that zero is unavailable
+	states [chunkWidth * chunkWidth]int8
+
+	// listIndex is the index of the cell in the list it's kept in,
+	// which are either the unoccupied cells, or the cells of its player
+	listIndex [chunkWidth * chunkWidth]int32
+}
+
+func (c *cellChunk) state(i int) CellState {
+	return CellState(c.states[i]) + CellUnavailable
+}
+
+func (c *cellChunk) setState(i int, state CellState) {
+	c.states[i] = int8(state - CellUnavailable)
+}
+
+// cellGrid stores an unbounded plane of cells. Chunks are allocated once
+// any of their cells is set, and are kept until the grid is dropped
+type cellGrid struct {
+	chunks map[Offset]*cellChunk
+
+	// The chunk looked up last for a change. Moves change the cells close
+	// to each other, so it saves most of the map lookups
+	lastKey   Offset
+	lastChunk *cellChunk
+}
+
+func newCellGrid() cellGrid {
+	return cellGrid{chunks: make(map[Offset]*cellChunk)}
+}
+
+// chunkPos returns the key of the chunk of the cell, and the index of the cell in it.
+// Shifts round towards negative infinity, so the chunks don't overlap around zero
+func chunkPos(pos Offset) (Offset, int) {
+	key := Offset{X: pos.X >> chunkShift, Y: pos.Y >> chunkShift}
+	return key, (pos.Y&chunkMask)<<chunkShift | pos.X&chunkMask
+}
+
+// cellAt returns the chunk of the cell, which is nil if none of its cells
+// was ever set. It doesn't change the grid, but reads the chunk cached by
+// cellForChange, so it may be called concurrently only while the grid isn't changed
+func (g *cellGrid) cellAt(pos Offset) (*cellChunk, int) {
+	key, i := chunkPos(pos)
+	if g.lastChunk != nil && key == g.lastKey {
+		return g.lastChunk, i
+	}
+
+	return g.chunks[key], i
+}
+
+// cellForChange returns the chunk of the cell, allocating it if needed
+func (g *cellGrid) cellForChange(pos Offset) (*cellChunk, int) {
+	key, i := chunkPos(pos)
+	if g.lastChunk != nil && key == g.lastKey {
+		return g.lastChunk, i
+	}
+
+	chunk, ok := g.chunks[key]
+	if !ok {
+		chunk = &cellChunk{}
+		g.chunks[key] = chunk
+	}
+
+	g.lastKey, g.lastChunk = key, chunk
+	return chunk, i
+}
+
+// chunkArea is the chunks covering a rectangle, looked up all at once
+type chunkArea struct {
+	// corner is the key of the top-left chunk, and width is the number of chunks in a row
+	corner Offset
+	width  int
+	chunks []*cellChunk
+}
+
+// areaForChange looks up the chunks covering the rectangle, allocating them if needed.
+// The chunks are appended to the buffer, which is reused between the calls
+func (g *cellGrid) areaForChange(bound Rect, buffer []*cellChunk) chunkArea {
+	corner, _ := chunkPos(Offset{X: bound.X, Y: bound.Y})
+	far, _ := chunkPos(Offset{X: bound.X + bound.W - 1, Y: bound.Y + bound.H - 1})
+
+	area := chunkArea{corner: corner, width: far.X - corner.X + 1, chunks: buffer[:0]}
+	for y := corner.Y; y <= far.Y; y++ {
+		for x := corner.X; x <= far.X; x++ {
+			chunk, _ := g.cellForChange(Offset{X: x << chunkShift, Y: y << chunkShift})
+			area.chunks = append(area.chunks, chunk)
+		}
+	}
+
+	return area
+}
+
+// cellAt expects the cell to be inside the rectangle of the area
+func (a *chunkArea) cellAt(pos Offset) (*cellChunk, int) {
+	key, i := chunkPos(pos)
+	return a.chunks[(key.Y-a.corner.Y)*a.width+key.X-a.corner.X], i
+}
+
+func (g *cellGrid) state(pos Offset) CellState {
+	chunk, i := g.cellAt(pos)
+	if chunk == nil {
+		return CellUnavailable
+	}
+
+	return chunk.state(i)
+}
+
+// forEach calls the function for each available cell
+func (g *cellGrid) forEach(f func(pos Offset, state CellState)) {
+	for key, chunk := range g.chunks {
+		for i, state := range chunk.states {
+			if state == 0 {
+				continue
+			}
+
+			pos := Offset{X: key.X<<chunkShift | i&chunkMask, Y: key.Y<<chunkShift | i>>chunkShift}
+			f(pos, CellState(state)+CellUnavailable)
+		}
+	}
+}
+
+func (g *cellGrid) clone() cellGrid {
+	clone := cellGrid{chunks: make(map[Offset]*cellChunk, len(g.chunks))}
+	for key, chunk := range g.chunks {
+		chunkCopy := *chunk
+		clone.chunks[key] = &chunkCopy
+	}
+
+	return clone
+}
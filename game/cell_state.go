@@ -7,10 +7,14 @@ package game
 type CellState int
 
 const (
-	CellUnavailable CellState = iota - 2
+	// CellBlocked is an obstacle that never holds a stone and breaks strikes
+	CellBlocked CellState = iota - 3
+	CellUnavailable
 	CellUnoccupied
 	CellP1
 	CellP2
+	CellP3
+	CellP4
 )
 
 func (cs CellState) IsOccupiedBy(player PlayerID) bool {
@@ -0,0 +1,169 @@
+package game
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeControl describes how much time players have to think. Sudden death
+// has only the main time, Fischer clocks add an increment and byo-yomi
+// gives extra periods once the main time runs out
+type TimeControl struct {
+	// MainTime is the time each player has for the whole game
+	MainTime time.Duration
+
+	// Increment is added to the main time after each turn made within the main time
+	Increment time.Duration
+
+	// ByoYomiPeriods is the number of periods players get after the main time runs out.
+	// A turn made within a period keeps it, otherwise the next one begins
+	ByoYomiPeriods int
+	ByoYomiTime    time.Duration
+}
+
+// PlayerTime is the time a player has left
+type PlayerTime struct {
+	Main time.Duration
+
+	// Period is the time left in the current byo-yomi period,
+	// or the length of a period, if the main time hasn't run out yet
+	Period  time.Duration
+	Periods int
+
+	Flagged bool
+}
+
+// InByoYomi reports whether the main time has run out and the periods are used
+func (t PlayerTime) InByoYomi() bool {
+	return t.Main == 0 && !t.Flagged
+}
+
+// Clock keeps the time of each player. The time of a single player runs
+// at a time, which is the player to move. All the methods take the current
+// time explicitly, so that the clock can be driven by anything
+type Clock struct {
+	control TimeControl
+
+	mainLeft    []time.Duration
+	periodsLeft []int
+
+	running   bool
+	player    PlayerID
+	startedAt time.Time
+}
+
+func NewClock(control TimeControl, playerCount int) *Clock {
+	if control.MainTime < 0 || control.Increment < 0 || control.ByoYomiPeriods < 0 || control.ByoYomiTime < 0 {
+		panic(fmt.Sprintf("new clock: negative time control %+v", control))
+	}
+
+	if control.ByoYomiPeriods > 0 && control.ByoYomiTime == 0 {
+		panic(fmt.Sprintf("new clock: byo-yomi periods have no time (periods=%d)", control.ByoYomiPeriods))
+	}
+
+	if control.MainTime == 0 && control.ByoYomiPeriods == 0 {
+		panic("new clock: players have no time")
+	}
+
+	if playerCount < 2 {
+		panic(fmt.Sprintf("new clock: at least 2 players are required (player count=%d)", playerCount))
+	}
+
+	c := &Clock{
+		control:     control,
+		mainLeft:    make([]time.Duration, playerCount),
+		periodsLeft: make([]int, playerCount),
+	}
+
+	for p := range c.mainLeft {
+		c.mainLeft[p] = control.MainTime
+		c.periodsLeft[p] = control.ByoYomiPeriods
+	}
+
+	return c
+}
+
+func (c *Clock) TimeControl() TimeControl {
+	return c.control
+}
+
+// Running returns the player whose time is running, if any
+func (c *Clock) Running() (PlayerID, bool) {
+	return c.player, c.running
+}
+
+// Start runs the time of the player
+func (c *Clock) Start(player PlayerID, now time.Time) {
+	if c.running {
+		panic(fmt.Sprintf("clock: start %v: the time of %v is already running", player, c.player))
+	}
+
+	if !player.IsValid(len(c.mainLeft)) {
+		panic(fmt.Sprintf("clock: start %v: invalid player (player count=%d)", player, len(c.mainLeft)))
+	}
+
+	c.running = true
+	c.player = player
+	c.startedAt = now
+}
+
+// Stop stops the running time after the player has made their turn.
+// The increment is added, if the turn was made within the main time.
+// Stop reports whether the player has run out of time before the turn
+func (c *Clock) Stop(now time.Time) (flagged bool) {
+	if !c.running {
+		panic("clock: stop: no time is running")
+	}
+
+	left := c.TimeLeft(c.player, now)
+	c.running = false
+
+	switch {
+	case left.Flagged:
+		c.mainLeft[c.player] = 0
+		c.periodsLeft[c.player] = 0
+		return true
+
+	case left.Main > 0:
+		c.mainLeft[c.player] = left.Main + c.control.Increment
+
+	default:
+		// The current period begins anew with the next turn
+		c.mainLeft[c.player] = 0
+		c.periodsLeft[c.player] = left.Periods
+	}
+
+	return false
+}
+
+// Flagged reports whether the player has run out of time
+func (c *Clock) Flagged(player PlayerID, now time.Time) bool {
+	return c.TimeLeft(player, now).Flagged
+}
+
+func (c *Clock) TimeLeft(player PlayerID, now time.Time) PlayerTime {
+	var elapsed time.Duration
+	if c.running && c.player == player {
+		elapsed = now.Sub(c.startedAt)
+	}
+
+	main := c.mainLeft[player]
+	periods := c.periodsLeft[player]
+	period := c.control.ByoYomiTime
+
+	if elapsed < main {
+		return PlayerTime{Main: main - elapsed, Period: period, Periods: periods}
+	}
+
+	if periods == 0 {
+		return PlayerTime{Flagged: true}
+	}
+
+	over := elapsed - main
+	used := int(over / period)
+	if used >= periods {
+		return PlayerTime{Flagged: true}
+	}
+
+	return PlayerTime{Period: period - over%period, Periods: periods - used}
+}
@@ -0,0 +1,90 @@
+package game_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"github.com/kitsunemikan/six-purrpurrs/game"
+)
+
+func TestClock(t *testing.T) {
+	start := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) time.Time {
+		return start.Add(d)
+	}
+
+	t.Run("sudden death", func(t *testing.T) {
+		clock := game.NewClock(game.TimeControl{MainTime: time.Minute}, 2)
+
+		clock.Start(game.P1, at(0))
+		td.Cmp(t, clock.TimeLeft(game.P1, at(20*time.Second)), game.PlayerTime{Main: 40 * time.Second})
+		td.Cmp(t, clock.TimeLeft(game.P2, at(20*time.Second)), game.PlayerTime{Main: time.Minute})
+
+		td.CmpFalse(t, clock.Stop(at(20*time.Second)))
+
+		clock.Start(game.P2, at(20*time.Second))
+		td.CmpFalse(t, clock.Flagged(game.P2, at(79*time.Second)))
+		td.CmpTrue(t, clock.Flagged(game.P2, at(80*time.Second)))
+		td.CmpTrue(t, clock.Stop(at(90*time.Second)))
+
+		td.Cmp(t, clock.TimeLeft(game.P1, at(90*time.Second)), game.PlayerTime{Main: 40 * time.Second})
+	})
+
+	t.Run("fischer", func(t *testing.T) {
+		clock := game.NewClock(game.TimeControl{MainTime: time.Minute, Increment: 5 * time.Second}, 2)
+
+		clock.Start(game.P1, at(0))
+		td.CmpFalse(t, clock.Stop(at(10*time.Second)))
+		td.Cmp(t, clock.TimeLeft(game.P1, at(10*time.Second)), game.PlayerTime{Main: 55 * time.Second})
+
+		// No increment for a turn made too late
+		clock.Start(game.P1, at(10*time.Second))
+		td.CmpTrue(t, clock.Stop(at(70*time.Second)))
+		td.CmpTrue(t, clock.Flagged(game.P1, at(70*time.Second)))
+	})
+
+	t.Run("byo-yomi", func(t *testing.T) {
+		clock := game.NewClock(game.TimeControl{
+			MainTime:       10 * time.Second,
+			ByoYomiPeriods: 3,
+			ByoYomiTime:    30 * time.Second,
+		}, 2)
+
+		clock.Start(game.P1, at(0))
+		td.Cmp(t, clock.TimeLeft(game.P1, at(5*time.Second)), game.PlayerTime{
+			Main:    5 * time.Second,
+			Period:  30 * time.Second,
+			Periods: 3,
+		})
+
+		// A period is used up, the next one is running
+		left := clock.TimeLeft(game.P1, at(50*time.Second))
+		td.Cmp(t, left, game.PlayerTime{Period: 20 * time.Second, Periods: 2})
+		td.CmpTrue(t, left.InByoYomi())
+
+		td.CmpFalse(t, clock.Stop(at(50*time.Second)))
+		td.Cmp(t, clock.TimeLeft(game.P1, at(50*time.Second)), game.PlayerTime{Period: 30 * time.Second, Periods: 2})
+
+		// A turn within a period keeps it
+		clock.Start(game.P1, at(50*time.Second))
+		td.CmpFalse(t, clock.Stop(at(79*time.Second)))
+		td.Cmp(t, clock.TimeLeft(game.P1, at(79*time.Second)), game.PlayerTime{Period: 30 * time.Second, Periods: 2})
+
+		clock.Start(game.P1, at(79*time.Second))
+		td.CmpFalse(t, clock.Flagged(game.P1, at(138*time.Second)))
+		td.CmpTrue(t, clock.Flagged(game.P1, at(139*time.Second)))
+	})
+
+	t.Run("misuse", func(t *testing.T) {
+		td.CmpPanic(t, func() { game.NewClock(game.TimeControl{}, 2) }, td.Contains("no time"))
+		td.CmpPanic(t, func() { game.NewClock(game.TimeControl{MainTime: time.Minute, ByoYomiPeriods: 1}, 2) }, td.Contains("byo-yomi"))
+
+		clock := game.NewClock(game.TimeControl{MainTime: time.Minute}, 2)
+		td.CmpPanic(t, func() { clock.Stop(at(0)) }, td.Contains("no time is running"))
+
+		clock.Start(game.P1, at(0))
+		td.CmpPanic(t, func() { clock.Start(game.P2, at(0)) }, td.Contains("already running"))
+	})
+}
@@ -30,7 +30,7 @@ func (ch *EightDirStrikeVictoryChecker) CheckAt(strikes *StrikeSet, pos geom.Off
 func (ch *EightDirStrikeVictoryChecker) CandidatesAroundFor(strikes *StrikeSet, pos geom.Offset, player PlayerID) []geom.Offset {
 	var candidates []geom.Offset
 
-	for _, dir := range StrikeDirs {
+	for _, dir := range strikes.Dirs() {
 		// Forward direction
 		afterCell := pos.Add(dir.Offset())
 		afterStrike := strikes.StrikesThrough(afterCell)[dir.FixedID]
@@ -52,8 +52,12 @@ func (ch *EightDirStrikeVictoryChecker) CandidatesAroundFor(strikes *StrikeSet,
 }
 
 func (ch *EightDirStrikeVictoryChecker) Clone() VictoryChecker {
-	strikeCopy := make([]geom.Offset, 0, len(ch.strike))
-	strikeCopy = append(strikeCopy, ch.strike...)
+	// Reached() relies on the strike being nil
+	var strikeCopy []geom.Offset
+	if ch.strike != nil {
+		strikeCopy = make([]geom.Offset, len(ch.strike))
+		copy(strikeCopy, ch.strike)
+	}
 
 	return &EightDirStrikeVictoryChecker{
 		VictoryLength: ch.VictoryLength,
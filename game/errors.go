@@ -0,0 +1,17 @@
+package game
+
+import "errors"
+
+// Sentinel errors returned by the validated move API, check them with errors.Is
+var (
+	ErrCellOccupied    = errors.New("cell is occupied")
+	ErrCellUnoccupied  = errors.New("cell is unoccupied")
+	ErrCellUnavailable = errors.New("cell is unavailable")
+	ErrGameOver        = errors.New("game is over")
+	ErrNotYourTurn     = errors.New("not your turn")
+	ErrNoMoves         = errors.New("no moves have been made")
+
+	// ErrNotTwoPlayers is returned by Resign and LoseOnTime in games of more than two
+	// players, since there's no single opponent to win then
+	ErrNotTwoPlayers = errors.New("only two-player games can be lost this way")
+)
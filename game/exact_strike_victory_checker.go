@@ -0,0 +1,150 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/kitsunemikan/six-purrpurrs/geom"
+)
+
+// OverlinePolicy specifies how strikes longer than the victory length are treated
+type OverlinePolicy int
+
+const (
+	// OverlineAllowed makes an overline win the game, the same as a strike of exact length
+	OverlineAllowed OverlinePolicy = iota
+
+	// OverlineNotCounted makes an overline have no effect on the game
+	OverlineNotCounted
+
+	// OverlineLoses makes the player who made an overline lose the game.
+	// The victory goes to the next player, which in games of more than
+	// two players leaves the others beaten as well
+	OverlineLoses
+)
+
+func (op OverlinePolicy) String() string {
+	switch op {
+	case OverlineAllowed:
+		return "allowed"
+	case OverlineNotCounted:
+		return "notcounted"
+	case OverlineLoses:
+		return "loses"
+	}
+
+	return fmt.Sprintf("UnknownOverlinePolicy%d", int(op))
+}
+
+// ExactStrikeVictoryChecker declares a victory for strikes of exactly VictoryLength cells.
+// Longer strikes, overlines, are treated according to the Overline policy.
+type ExactStrikeVictoryChecker struct {
+	VictoryLength int
+	Overline      OverlinePolicy
+
+	// PlayerCount is used to determine the winner, when an overline loses.
+	// The victory is given to the player following the one who made the overline
+	PlayerCount int
+
+	strike []geom.Offset
+	player PlayerID
+}
+
+func (ch *ExactStrikeVictoryChecker) StrikeLength() int {
+	return ch.VictoryLength
+}
+
+func (ch *ExactStrikeVictoryChecker) CheckAt(strikes *StrikeSet, pos geom.Offset) bool {
+	cellStrikes := strikes.StrikesThrough(pos)
+
+	// An exact strike takes precedence over an overline made with the same move
+	for strikeID := range cellStrikes {
+		if cellStrikes[strikeID].Len == ch.VictoryLength {
+			ch.strike = cellStrikes[strikeID].AsCells()
+			ch.player = cellStrikes[strikeID].Player
+			return true
+		}
+	}
+
+	for strikeID := range cellStrikes {
+		if cellStrikes[strikeID].Len <= ch.VictoryLength {
+			continue
+		}
+
+		switch ch.Overline {
+		case OverlineAllowed:
+			ch.strike = cellStrikes[strikeID].AsCells()
+			ch.player = cellStrikes[strikeID].Player
+			return true
+
+		case OverlineLoses:
+			ch.strike = cellStrikes[strikeID].AsCells()
+			ch.player = cellStrikes[strikeID].Player.NextPlayer(ch.PlayerCount)
+			return true
+		}
+	}
+
+	return false
+}
+
+// CandidatesAroundFor skips the strikes that would join into an overline,
+// unless overlines are allowed
+func (ch *ExactStrikeVictoryChecker) CandidatesAroundFor(strikes *StrikeSet, pos geom.Offset, player PlayerID) []geom.Offset {
+	var candidates []geom.Offset
+
+	for _, dir := range strikes.Dirs() {
+		afterStrike := strikes.StrikesThrough(pos.Add(dir.Offset()))[dir.FixedID]
+		if afterStrike.Player != player {
+			afterStrike.Len = 0
+		}
+
+		beforeStrike := strikes.StrikesThrough(pos.Sub(dir.Offset()))[dir.FixedID]
+		if beforeStrike.Player != player {
+			beforeStrike.Len = 0
+		}
+
+		joinedLen := beforeStrike.Len + 1 + afterStrike.Len
+		if joinedLen > ch.VictoryLength && ch.Overline != OverlineAllowed {
+			continue
+		}
+
+		candidates = append(candidates, afterStrike.AsCells()...)
+		candidates = append(candidates, beforeStrike.AsCells()...)
+	}
+
+	return candidates
+}
+
+func (ch *ExactStrikeVictoryChecker) Clone() VictoryChecker {
+	// Reached() relies on the strike being nil
+	var strikeCopy []geom.Offset
+	if ch.strike != nil {
+		strikeCopy = make([]geom.Offset, len(ch.strike))
+		copy(strikeCopy, ch.strike)
+	}
+
+	return &ExactStrikeVictoryChecker{
+		VictoryLength: ch.VictoryLength,
+		Overline:      ch.Overline,
+		PlayerCount:   ch.PlayerCount,
+
+		strike: strikeCopy,
+		player: ch.player,
+	}
+}
+
+func (ch *ExactStrikeVictoryChecker) Reset() {
+	ch.strike = nil
+	ch.player = P1
+}
+
+func (ch *ExactStrikeVictoryChecker) Reached() bool {
+	return ch.strike != nil
+}
+
+func (ch *ExactStrikeVictoryChecker) VictoriousStrike() []geom.Offset {
+	return ch.strike
+}
+
+func (ch *ExactStrikeVictoryChecker) VictoriousPlayer() PlayerID {
+	return ch.player
+}
@@ -0,0 +1,127 @@
+package game_test
+
+import (
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"github.com/kitsunemikan/six-purrpurrs/game"
+	"github.com/kitsunemikan/six-purrpurrs/geom"
+)
+
+func TestExactStrikeVictoryChecker(t *testing.T) {
+	// P1 places stones on the X axis, P2 places stones two rows below with gaps
+	// between them, so that it doesn't interfere. The cells are within the border
+	// of the stones already placed
+	rowMoves := func(xs ...int) []game.PlayerMove {
+		var moves []game.PlayerMove
+		for i, x := range xs {
+			moves = append(moves,
+				game.PlayerMove{Cell: geom.Offset{X: x, Y: 0}, Player: game.P1},
+				game.PlayerMove{Cell: geom.Offset{X: 2 * i, Y: 2}, Player: game.P2},
+			)
+		}
+
+		return moves[:len(moves)-1]
+	}
+
+	cases := []struct {
+		desc        string
+		overline    game.OverlinePolicy
+		moves       []game.PlayerMove
+		wantReached bool
+		wantWinner  game.PlayerID
+		wantStrike  []geom.Offset
+	}{
+		{
+			"exact strike wins",
+			game.OverlineNotCounted,
+			rowMoves(0, 1, 2),
+			true,
+			game.P1,
+			[]geom.Offset{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}},
+		},
+		{
+			"allowed overline wins",
+			game.OverlineAllowed,
+			rowMoves(0, 1, 3, 4, 2),
+			true,
+			game.P1,
+			[]geom.Offset{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}, {X: 3, Y: 0}, {X: 4, Y: 0}},
+		},
+		{
+			"not counted overline doesn't win",
+			game.OverlineNotCounted,
+			rowMoves(0, 1, 3, 4, 2),
+			false,
+			game.P1,
+			nil,
+		},
+		{
+			"overline loses",
+			game.OverlineLoses,
+			rowMoves(0, 1, 3, 4, 2),
+			true,
+			game.P2,
+			[]geom.Offset{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}, {X: 3, Y: 0}, {X: 4, Y: 0}},
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.desc, func(t *testing.T) {
+			g := game.NewGame(game.GameOptions{
+				Border:      2,
+				PlayerCount: 2,
+				Victory: &game.ExactStrikeVictoryChecker{
+					VictoryLength: 3,
+					Overline:      test.overline,
+					PlayerCount:   2,
+				},
+			})
+
+			for _, move := range test.moves {
+				td.CmpNoError(t, g.TryMove(move.Cell, move.Player))
+			}
+
+			if g.Over() != test.wantReached {
+				t.Fatalf("got game over %v, want %v", g.Over(), test.wantReached)
+			}
+
+			if !test.wantReached {
+				return
+			}
+
+			if g.Winner() != test.wantWinner {
+				t.Errorf("got winner %v, want %v", g.Winner(), test.wantWinner)
+			}
+
+			td.Cmp(t, g.VictoriousStrike(), test.wantStrike)
+		})
+	}
+}
+
+func TestExactStrikeVictoryCheckerCandidates(t *testing.T) {
+	// XX.XX
+	g := game.NewGame(game.GameOptions{
+		Border:      1,
+		PlayerCount: 2,
+		Victory: &game.ExactStrikeVictoryChecker{
+			VictoryLength: 4,
+			Overline:      game.OverlineNotCounted,
+			PlayerCount:   2,
+		},
+	})
+
+	td.CmpNoError(t, g.MarkCell(geom.Offset{X: 0, Y: 0}, game.P1))
+	td.CmpNoError(t, g.MarkCell(geom.Offset{X: 1, Y: 0}, game.P1))
+	td.CmpNoError(t, g.MarkCell(geom.Offset{X: 3, Y: 0}, game.P1))
+	td.CmpNoError(t, g.MarkCell(geom.Offset{X: 4, Y: 0}, game.P1))
+
+	// Joining at (2;0) would make a 5-len overline, so no horizontal candidates
+	got := g.CandidatesAroundFor(geom.Offset{X: 2, Y: 0}, game.P1)
+	td.Cmp(t, got, td.Nil())
+
+	// Extending at (-1;0) makes an exact 3-len strike
+	got = g.CandidatesAroundFor(geom.Offset{X: -1, Y: 0}, game.P1)
+	td.Cmp(t, got, td.Bag(geom.Offset{X: 0, Y: 0}, geom.Offset{X: 1, Y: 0}))
+}
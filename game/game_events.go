@@ -0,0 +1,91 @@
+package game
+
+import "fmt"
+
+// GameEvent is a change of a game state, which is sent to its observers.
+// It's one of MoveMadeEvent, MoveUndoneEvent, GameOverEvent and GameResetEvent
+type GameEvent interface {
+	fmt.Stringer
+	isGameEvent()
+}
+
+// MoveMadeEvent is sent after a stone is placed
+type MoveMadeEvent struct {
+	Move PlayerMove
+
+	// MoveNumber is the number of the move, starting from 1
+	MoveNumber int
+}
+
+// MoveUndoneEvent is sent after a stone is taken back
+type MoveUndoneEvent struct {
+	Move       PlayerMove
+	MoveNumber int
+}
+
+// GameOverEvent is sent once the game ends, either with a victory,
+// a draw, or an adjudicated result. It follows the MoveMadeEvent
+// of the move that ended the game
+type GameOverEvent struct {
+	Result GameResult
+}
+
+// GameResetEvent is sent after all the moves are taken back at once
+type GameResetEvent struct{}
+
+func (MoveMadeEvent) isGameEvent()   {}
+func (MoveUndoneEvent) isGameEvent() {}
+func (GameOverEvent) isGameEvent()   {}
+func (GameResetEvent) isGameEvent()  {}
+
+func (e MoveMadeEvent) String() string {
+	return fmt.Sprintf("move %d made: %v at %v", e.MoveNumber, e.Move.Player, e.Move.Cell)
+}
+
+func (e MoveUndoneEvent) String() string {
+	return fmt.Sprintf("move %d undone: %v at %v", e.MoveNumber, e.Move.Player, e.Move.Cell)
+}
+
+func (e GameOverEvent) String() string {
+	return fmt.Sprintf("game over: %v", e.Result)
+}
+
+func (e GameResetEvent) String() string {
+	return "game reset"
+}
+
+type gameObserver struct {
+	id     int
+	notify func(GameEvent)
+}
+
+// Subscribe makes the observer receive the events of the game, until unsubscribed.
+// Observers are notified synchronously, in the order of subscription, by the
+// goroutine changing the game, and they must not change the game themselves
+func (g *GameState) Subscribe(observer func(GameEvent)) (unsubscribe func()) {
+	g.lastObserverID++
+	id := g.lastObserverID
+
+	g.observers = append(g.observers, gameObserver{id, observer})
+
+	return func() {
+		for i := range g.observers {
+			if g.observers[i].id == id {
+				g.observers = append(g.observers[:i:i], g.observers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func (g *GameState) notify(event GameEvent) {
+	// Observers may unsubscribe while being notified
+	observers := g.observers
+	for _, observer := range observers {
+		observer.notify(event)
+	}
+}
+
+func (g *GameState) observed() bool {
+	return len(g.observers) > 0
+}
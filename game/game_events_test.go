@@ -0,0 +1,78 @@
+package game_test
+
+import (
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"github.com/kitsunemikan/six-purrpurrs/game"
+	"github.com/kitsunemikan/six-purrpurrs/geom"
+)
+
+func TestGameStateEvents(t *testing.T) {
+	g := game.NewGame(game.GameOptions{
+		Border:      3,
+		PlayerCount: 2,
+		Turns:       game.SingleStoneTurns,
+		Victory:     &game.EightDirStrikeVictoryChecker{VictoryLength: 2},
+	})
+
+	var events []game.GameEvent
+	unsubscribe := g.Subscribe(func(event game.GameEvent) {
+		events = append(events, event)
+	})
+
+	td.CmpNoError(t, g.Play(geom.Offset{X: 0, Y: 0}))
+	td.CmpNoError(t, g.Play(geom.Offset{X: 3, Y: 0}))
+	td.CmpNoError(t, g.Play(geom.Offset{X: 1, Y: 0}))
+	g.UndoLastMove()
+	td.CmpNoError(t, g.Resign(game.P1))
+	g.Reset()
+
+	p1 := game.PlayerMove{Cell: geom.Offset{X: 0, Y: 0}, Player: game.P1}
+	p2 := game.PlayerMove{Cell: geom.Offset{X: 3, Y: 0}, Player: game.P2}
+	win := game.PlayerMove{Cell: geom.Offset{X: 1, Y: 0}, Player: game.P1}
+
+	td.Cmp(t, events, []game.GameEvent{
+		game.MoveMadeEvent{Move: p1, MoveNumber: 1},
+		game.MoveMadeEvent{Move: p2, MoveNumber: 2},
+		game.MoveMadeEvent{Move: win, MoveNumber: 3},
+		game.GameOverEvent{Result: game.GameResult{Outcome: game.OutcomeStrike, Winner: game.P1}},
+		game.MoveUndoneEvent{Move: win, MoveNumber: 3},
+		game.GameOverEvent{Result: game.GameResult{Outcome: game.OutcomeResignation, Winner: game.P2}},
+		game.GameResetEvent{},
+	})
+
+	td.Cmp(t, g.MoveNumber(), 1)
+	td.CmpFalse(t, g.Over())
+
+	events = nil
+	unsubscribe()
+	td.CmpNoError(t, g.Play(geom.Offset{X: 0, Y: 0}))
+	td.Cmp(t, events, td.Nil())
+}
+
+func TestGameStateUnsubscribeWhileNotified(t *testing.T) {
+	g := game.NewGame(game.GameOptions{
+		Border:      3,
+		PlayerCount: 2,
+		Victory:     &game.EightDirStrikeVictoryChecker{VictoryLength: 6},
+	})
+
+	var first, second int
+	var unsubscribeFirst func()
+	unsubscribeFirst = g.Subscribe(func(game.GameEvent) {
+		first++
+		unsubscribeFirst()
+	})
+
+	g.Subscribe(func(game.GameEvent) {
+		second++
+	})
+
+	td.CmpNoError(t, g.Play(geom.Offset{X: 0, Y: 0}))
+	td.CmpNoError(t, g.Play(geom.Offset{X: 1, Y: 0}))
+
+	td.Cmp(t, first, 1)
+	td.Cmp(t, second, 2)
+}
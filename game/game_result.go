@@ -0,0 +1,83 @@
+package game
+
+import "fmt"
+
+// Outcome tells how the game ended, or that it's still going on
+type Outcome int
+
+const (
+	OutcomeOngoing Outcome = iota
+
+	// OutcomeStrike is a win by completing a strike
+	OutcomeStrike
+
+	// OutcomeResignation is a win, because the opponent resigned
+	OutcomeResignation
+
+	// OutcomeTimeout is a win, because the opponent ran out of time
+	OutcomeTimeout
+
+	// OutcomeAgreement is a draw the players agreed to
+	OutcomeAgreement
+
+	// OutcomeBoardFull is a draw, because there are no cells left to move to
+	OutcomeBoardFull
+
+	// OutcomeMoveLimit is a draw, because the players made the maximum number of moves
+	OutcomeMoveLimit
+
+	// OutcomeNoWinningWindow is a draw, because no player can complete a strike anymore
+	OutcomeNoWinningWindow
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeOngoing:
+		return "ongoing"
+	case OutcomeStrike:
+		return "win by strike"
+	case OutcomeResignation:
+		return "win by resignation"
+	case OutcomeTimeout:
+		return "win on time"
+	case OutcomeAgreement:
+		return "draw by agreement"
+	case OutcomeBoardFull:
+		return "draw by full board"
+	case OutcomeMoveLimit:
+		return "draw by move limit"
+	case OutcomeNoWinningWindow:
+		return "draw by no winning strikes left"
+	}
+
+	return fmt.Sprintf("UnknownOutcome%d", int(o))
+}
+
+// IsWin reports whether the outcome has a winner
+func (o Outcome) IsWin() bool {
+	return o == OutcomeStrike || o == OutcomeResignation || o == OutcomeTimeout
+}
+
+// GameResult is the outcome of a game
+type GameResult struct {
+	Outcome Outcome `json:"outcome"`
+
+	// Winner is valid only when the outcome is a win
+	Winner PlayerID `json:"winner"`
+}
+
+func (r GameResult) Over() bool {
+	return r.Outcome != OutcomeOngoing
+}
+
+func (r GameResult) IsDraw() bool {
+	return r.Over() && !r.Outcome.IsWin()
+}
+
+func (r GameResult) String() string {
+	if r.Outcome.IsWin() {
+		return fmt.Sprintf("%v: %v", r.Winner, r.Outcome)
+	}
+
+	return r.Outcome.String()
+}
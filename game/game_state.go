@@ -1,35 +1,249 @@
 package game
 
 import (
+	"fmt"
+
 	. "github.com/kitsunemikan/six-purrpurrs/geom"
 )
 
 var solutionOffsets = []Offset{{X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 1}, {X: 1, Y: -1}}
 
 type GameOptions struct {
+	// Border is the width of the area revealed around each move on an expanding board
 	Border int
 
+	// Topology is the shape of the board cells, SquareTopology by default
+	Topology Topology
+
+	// BoardSize fixes the board to a rectangle of the given dimensions centered
+	// at the origin. The board expands infinitely around the moves, if zero
+	BoardSize Offset
+
+	// Wrap glues the opposite edges of a bounded board together,
+	// so strikes may continue past one edge onto the other
+	Wrap bool
+
+	// Layout shapes the board and places obstacles. The board is bounded
+	// to the layout size, so BoardSize must be either zero, or the same
+	Layout *Layout
+
+	// PlayerCount is the number of players taking turns, at least 2
+	PlayerCount int
+
+	// Turns decides who places the next stone. SingleStoneTurns is used, if nil
+	Turns TurnPolicy
+
+	// Rules restrict where players may move. Any unoccupied cell is allowed, if nil
+	Rules MoveRules
+
 	Victory VictoryChecker
+
+	// MoveLimit ends the game in a draw after the given number of stones
+	// were placed without a victory. There's no limit, if zero
+	MoveLimit int
+
+	// DrawWithoutWindows ends a game on a bounded board in a draw, once there's
+	// no line of cells left where any player could still complete a strike
+	DrawWithoutWindows bool
 }
 
 type GameState struct {
 	Board      *BoardState
 	StrikeStat *StrikeSet
 
-	victory VictoryChecker
+	playerCount int
+	layout      *Layout
+	turns       TurnPolicy
+	rules       MoveRules
+	victory     VictoryChecker
+
+	moveLimit          int
+	drawWithoutWindows bool
+	adjudicated        GameResult
+
+	// The window search is costly, so its result is kept until the board changes
+	windowsChecked bool
+	windowsLeft    bool
+
+	observers      []gameObserver
+	lastObserverID int
+}
+
+// Validate checks that a game may be started with the options, which may come
+// from an untrusted source, like a saved game. NewGame panics on the options it refuses
+func (opts GameOptions) Validate() error {
+	if opts.PlayerCount < 2 {
+		return fmt.Errorf("game options: at least 2 players are required (player count=%d)", opts.PlayerCount)
+	}
+
+	if opts.Topology != SquareTopology && opts.Topology != HexTopology {
+		return fmt.Errorf("game options: unknown topology %v", opts.Topology)
+	}
+
+	if opts.Border < 0 {
+		return fmt.Errorf("game options: negative border (border=%d)", opts.Border)
+	}
+
+	if !opts.BoardSize.IsZero() && (opts.BoardSize.X < 1 || opts.BoardSize.Y < 1) {
+		return fmt.Errorf("game options: board size %v must be positive", opts.BoardSize)
+	}
+
+	if opts.Layout != nil && !opts.BoardSize.IsZero() && !opts.BoardSize.IsEqual(opts.Layout.Size) {
+		return fmt.Errorf("game options: board size %v doesn't match the layout size %v", opts.BoardSize, opts.Layout.Size)
+	}
+
+	if opts.Wrap && opts.BoardSize.IsZero() && opts.Layout == nil {
+		return fmt.Errorf("game options: only bounded boards may wrap")
+	}
+
+	if opts.MoveLimit < 0 {
+		return fmt.Errorf("game options: negative move limit (move limit=%d)", opts.MoveLimit)
+	}
+
+	if rules, renju := opts.Rules.(RenjuRules); renju && !rules.Restricted.IsValid(opts.PlayerCount) {
+		return fmt.Errorf("game options: renju restricts player %v in a game of %d players", rules.Restricted, opts.PlayerCount)
+	}
+
+	if opts.Victory == nil {
+		return fmt.Errorf("game options: no victory checker")
+	}
+
+	if opts.Victory.StrikeLength() < 1 {
+		return fmt.Errorf("game options: victory needs strikes of at least 1 stone (strike length=%d)", opts.Victory.StrikeLength())
+	}
+
+	return nil
 }
 
 func NewGame(conf GameOptions) *GameState {
+	if err := conf.Validate(); err != nil {
+		panic(fmt.Sprintf("new game: %v", err))
+	}
+
+	if conf.Turns == nil {
+		conf.Turns = SingleStoneTurns
+	}
+
+	if conf.Layout != nil {
+		conf.BoardSize = conf.Layout.Size
+	}
+
+	bound := NewRectFromOffsets(Offset{}, conf.BoardSize).CenterOn(Offset{})
+
+	var board *BoardState
+	strikes := NewStrikeSetWithDirs(conf.Topology.StrikeDirs())
+	switch {
+	case conf.Wrap:
+		board = NewWrappingBoardState(conf.Topology, bound, conf.PlayerCount)
+		strikes = NewWrappingStrikeSet(conf.Topology.StrikeDirs(), bound)
+	case !conf.BoardSize.IsZero():
+		board = NewBoundedBoardState(conf.Topology, bound, conf.PlayerCount)
+		strikes = NewBoundedStrikeSet(conf.Topology.StrikeDirs(), bound)
+	default:
+		board = NewBoardStateWithTopology(conf.Topology, conf.Border, conf.PlayerCount)
+	}
+
+	if conf.Layout != nil {
+		board.applyLayout(conf.Layout)
+
+		// Holes end lines just like walls do
+		for y := 0; y < conf.Layout.Size.Y; y++ {
+			for x := 0; x < conf.Layout.Size.X; x++ {
+				switch conf.Layout.Cell(Offset{X: x, Y: y}) {
+				case CellUnavailable, CellBlocked:
+					strikes.MarkBlocked(bound.ToWorldXY(x, y))
+				}
+			}
+		}
+	}
+
 	g := &GameState{
-		Board:      NewBoardState(conf.Border),
-		StrikeStat: NewStrikeSet(),
+		Board:      board,
+		StrikeStat: strikes,
 
-		victory: conf.Victory,
+		playerCount: conf.PlayerCount,
+		layout:      conf.Layout,
+		turns:       conf.Turns,
+		rules:       conf.Rules,
+		victory:     conf.Victory,
+
+		moveLimit:          conf.MoveLimit,
+		drawWithoutWindows: conf.DrawWithoutWindows,
 	}
 
 	return g
 }
 
+func (g *GameState) PlayerCount() int {
+	return g.playerCount
+}
+
+func (g *GameState) Topology() Topology {
+	return g.Board.Topology()
+}
+
+func (g *GameState) TurnPolicy() TurnPolicy {
+	return g.turns
+}
+
+// PlayerToMove returns the player that should place the next stone
+func (g *GameState) PlayerToMove() PlayerID {
+	return g.turns.PlayerAt(g.MoveNumber(), g.playerCount)
+}
+
+// StonesLeftInTurn returns the number of stones, including the next one,
+// that the player to move has yet to place this turn
+func (g *GameState) StonesLeftInTurn() int {
+	return g.turns.StonesLeftAt(g.MoveNumber())
+}
+
+func (g *GameState) Rules() MoveRules {
+	return g.rules
+}
+
+// Layout returns the layout the board was shaped with, or nil. Do not modify the result
+func (g *GameState) Layout() *Layout {
+	return g.layout
+}
+
+// MoveLimit returns the number of stones after which the game ends in a draw, or zero
+func (g *GameState) MoveLimit() int {
+	return g.moveLimit
+}
+
+// DrawWithoutWindows tells whether the game ends in a draw,
+// once no player can complete a strike, see GameOptions
+func (g *GameState) DrawWithoutWindows() bool {
+	return g.drawWithoutWindows
+}
+
+// CheckMove returns a *ForbiddenMoveError, if the game rules forbid
+// the player to place a stone at an unoccupied cell
+func (g *GameState) CheckMove(pos Offset, player PlayerID) error {
+	if g.rules == nil {
+		return nil
+	}
+
+	return g.rules.CheckMove(g.StrikeStat, g.victory.StrikeLength(), pos, player)
+}
+
+// ForbiddenCells returns the unoccupied cells where the game rules
+// forbid the player to place a stone
+func (g *GameState) ForbiddenCells(player PlayerID) []Offset {
+	if g.rules == nil {
+		return nil
+	}
+
+	var forbidden []Offset
+	for _, cell := range g.Board.UnoccupiedCellList() {
+		if g.CheckMove(cell, player) != nil {
+			forbidden = append(forbidden, cell)
+		}
+	}
+
+	return forbidden
+}
+
 func (g *GameState) VictoryChecker() VictoryChecker {
 	return g.victory
 }
@@ -50,26 +264,267 @@ func (g *GameState) CandidatesAroundFor(cell Offset, player PlayerID) []Offset {
 	return g.victory.CandidatesAroundFor(g.StrikeStat, cell, player)
 }
 
+// Result tells whether the game has ended and why
+func (g *GameState) Result() GameResult {
+	switch {
+	case g.victory.Reached():
+		return GameResult{Outcome: OutcomeStrike, Winner: g.victory.VictoriousPlayer()}
+	case g.adjudicated.Over():
+		return g.adjudicated
+	case g.Board.IsFull():
+		return GameResult{Outcome: OutcomeBoardFull}
+	case g.moveLimit > 0 && g.Board.MoveCount() >= g.moveLimit:
+		return GameResult{Outcome: OutcomeMoveLimit}
+	case g.drawWithoutWindows && g.Board.Bounded() && !g.hasWinningWindow():
+		return GameResult{Outcome: OutcomeNoWinningWindow}
+	}
+
+	return GameResult{Outcome: OutcomeOngoing}
+}
+
 func (g *GameState) Over() bool {
-	return g.victory.Reached()
+	return g.Result().Over()
+}
+
+// IsDraw reports whether the game ended without a winner
+func (g *GameState) IsDraw() bool {
+	return g.Result().IsDraw()
+}
+
+// Adjudicate ends the game with a result that doesn't follow from the board,
+// e.g., a resignation, or a draw by agreement. Undoing a move takes the result back
+func (g *GameState) Adjudicate(result GameResult) error {
+	switch {
+	case g.Over():
+		return fmt.Errorf("game: adjudicate %v: %w", result, ErrGameOver)
+	case !result.Over():
+		return fmt.Errorf("game: adjudicate %v: the result doesn't end the game", result)
+	case result.Outcome == OutcomeStrike:
+		return fmt.Errorf("game: adjudicate %v: strikes are decided by the board", result)
+	case result.Outcome.IsWin() && !result.Winner.IsValid(g.playerCount):
+		return fmt.Errorf("game: adjudicate %v: invalid winner (player count=%d)", result, g.playerCount)
+	}
+
+	g.adjudicated = result
+
+	if g.observed() {
+		g.notify(GameOverEvent{result})
+	}
+
+	return nil
+}
+
+// AgreeDraw ends the game in a draw the players agreed to
+func (g *GameState) AgreeDraw() error {
+	return g.Adjudicate(GameResult{Outcome: OutcomeAgreement})
+}
+
+// Resign ends a two-player game with a win of the opponent
+func (g *GameState) Resign(player PlayerID) error {
+	return g.defeat(player, OutcomeResignation)
+}
+
+// LoseOnTime ends a two-player game with a win of the opponent, because
+// the player has run out of time
+func (g *GameState) LoseOnTime(player PlayerID) error {
+	return g.defeat(player, OutcomeTimeout)
+}
+
+func (g *GameState) defeat(player PlayerID, outcome Outcome) error {
+	if g.playerCount != 2 {
+		return fmt.Errorf("game: %v for %v: %w (player count=%d)", outcome, player, ErrNotTwoPlayers, g.playerCount)
+	}
+
+	if !player.IsValid(g.playerCount) {
+		return fmt.Errorf("game: %v for %v: invalid player", outcome, player)
+	}
+
+	return g.Adjudicate(GameResult{Outcome: outcome, Winner: player.NextPlayer(g.playerCount)})
+}
+
+// hasWinningWindow reports whether any player could still complete a strike,
+// that is, whether there's a line of strike length cells containing no obstacles
+// nor stones of different players. Overline rules aren't taken into account,
+// so a draw is never declared while a strike is still possible
+func (g *GameState) hasWinningWindow() bool {
+	if g.windowsChecked {
+		return g.windowsLeft
+	}
+
+	g.windowsChecked = true
+	g.windowsLeft = false
+
+	// Windows start on the unoccupied cells and the stones, never on the blocked ones
+	if g.hasWinningWindowFrom(g.Board.UnoccupiedCellList()) {
+		g.windowsLeft = true
+		return true
+	}
+
+	for player := 0; player < g.playerCount; player++ {
+		if g.hasWinningWindowFrom(g.Board.PlayerCellList(PlayerID(player))) {
+			g.windowsLeft = true
+			return true
+		}
+	}
+
+	return false
+}
+
+func (g *GameState) hasWinningWindowFrom(starts []Offset) bool {
+	length := g.victory.StrikeLength()
+	for _, start := range starts {
+		for _, dir := range g.StrikeStat.Dirs() {
+			if g.isWinningWindow(start, Offset{X: dir.X, Y: dir.Y}, length) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (g *GameState) isWinningWindow(start, dir Offset, length int) bool {
+	owner := CellUnoccupied
+	for i := 0; i < length; i++ {
+		cell := start.Add(dir.ScaleUp(i))
+
+		// On wrapping boards narrower than the strike length the window meets itself
+		if i > 0 && g.Board.Normalize(cell).IsEqual(start) {
+			return false
+		}
+
+		switch state := g.Cell(cell); {
+		case state == CellUnavailable, state == CellBlocked:
+			return false
+		case state == CellUnoccupied:
+		case owner == CellUnoccupied:
+			owner = state
+		case owner != state:
+			return false
+		}
+	}
+
+	return true
 }
 
 func (g *GameState) BoardBound() Rect {
 	return g.Board.BoardBound()
 }
 
-func (g *GameState) MarkCell(pos Offset, player PlayerID) {
+// ValidateMove checks whether the player may place a stone at pos right now.
+// The returned error wraps one of ErrGameOver, ErrNotYourTurn, ErrCellUnavailable
+// or ErrCellOccupied, or is a *ForbiddenMoveError
+func (g *GameState) ValidateMove(pos Offset, player PlayerID) error {
+	if g.Over() {
+		return fmt.Errorf("game: move by %v at %v: %w", player, pos, ErrGameOver)
+	}
+
+	if player != g.PlayerToMove() {
+		return fmt.Errorf("game: move by %v at %v: %w (player to move=%v)", player, pos, ErrNotYourTurn, g.PlayerToMove())
+	}
+
+	switch state := g.Cell(pos); {
+	case state == CellUnavailable, state == CellBlocked:
+		return fmt.Errorf("game: move by %v at %v: %w", player, pos, ErrCellUnavailable)
+	case state != CellUnoccupied:
+		return fmt.Errorf("game: move by %v at %v: %w (occupied by %v)", player, pos, ErrCellOccupied, PlayerID(state))
+	}
+
+	return g.CheckMove(pos, player)
+}
+
+// TryMove marks the cell, if the move is valid, see ValidateMove
+func (g *GameState) TryMove(pos Offset, player PlayerID) error {
+	if err := g.ValidateMove(pos, player); err != nil {
+		return err
+	}
+
+	return g.MarkCell(pos, player)
+}
+
+// Play places a stone of the player to move, if the move is valid, see ValidateMove
+func (g *GameState) Play(pos Offset) error {
+	return g.TryMove(pos, g.PlayerToMove())
+}
+
+// MarkCell doesn't check the game rules nor whose turn it is, so it's meant
+// for setting up positions and replaying validated moves, see Play and TryMove.
+// The returned error wraps ErrCellOccupied or ErrCellUnavailable, and the game is left as it was
+func (g *GameState) MarkCell(pos Offset, player PlayerID) error {
+	pos = g.Board.Normalize(pos)
+
+	if g.Board.Bounded() && g.Board.Cell(pos) == CellUnavailable {
+		return fmt.Errorf("game: mark cell at %v: %w", pos, ErrCellUnavailable)
+	}
+
+	// The result is costly to find, so it's only needed for the observers
+	wasOver := g.observed() && g.Over()
+
+	// The strike set refuses the same cells as the board, so it goes first,
+	// and nothing is changed if the move is refused
+	if err := g.StrikeStat.MakeMove(pos, player); err != nil {
+		return fmt.Errorf("game: mark cell: %w", err)
+	}
+
 	g.Board.MarkCell(pos, player)
-	g.StrikeStat.MakeMove(pos, player)
+	g.windowsChecked = false
 
 	g.victory.CheckAt(g.StrikeStat, pos)
+
+	if !g.observed() {
+		return nil
+	}
+
+	g.notify(MoveMadeEvent{Move: PlayerMove{pos, player}, MoveNumber: g.Board.MoveCount()})
+
+	if result := g.Result(); !wasOver && result.Over() {
+		g.notify(GameOverEvent{result})
+	}
+
+	return nil
+}
+
+// UndoLastMove takes back the latest stone, so the player
+// who placed it becomes the player to move again.
+// The returned error wraps ErrNoMoves, if there's nothing to take back
+func (g *GameState) UndoLastMove() error {
+	if g.Board.MoveCount() == 0 {
+		return fmt.Errorf("game: undo last move: %w", ErrNoMoves)
+	}
+
+	move := g.Board.LatestMove()
+	moveNumber := g.Board.MoveCount()
+
+	g.undoLastMove()
+
+	if g.observed() {
+		g.notify(MoveUndoneEvent{Move: move, MoveNumber: moveNumber})
+	}
+
+	return nil
+}
+
+// Reset takes back all the moves and the result at once
+func (g *GameState) Reset() {
+	for g.Board.MoveCount() > 0 {
+		g.undoLastMove()
+	}
+
+	g.adjudicated = GameResult{}
+
+	if g.observed() {
+		g.notify(GameResetEvent{})
+	}
 }
 
-func (g *GameState) UndoLastMove() {
+func (g *GameState) undoLastMove() {
+	g.adjudicated = GameResult{}
+
 	lastMove := g.Board.LatestMove()
 	g.StrikeStat.MarkUnoccupied(lastMove.Cell)
 
 	g.Board.UndoLastMove()
+	g.windowsChecked = false
 
 	if g.victory.Reached() {
 		g.victory.Reset()
@@ -84,6 +539,11 @@ func (g *GameState) VictoriousStrike() []Offset {
 	return g.victory.VictoriousStrike()
 }
 
-func (g *GameState) LatestMove() PlayerMove {
-	return g.Board.LatestMove()
+// LatestMove returns the last stone placed. The returned error wraps ErrNoMoves, if there are none
+func (g *GameState) LatestMove() (PlayerMove, error) {
+	if g.Board.MoveCount() == 0 {
+		return PlayerMove{}, fmt.Errorf("game: latest move: %w", ErrNoMoves)
+	}
+
+	return g.Board.LatestMove(), nil
 }
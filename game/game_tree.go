@@ -0,0 +1,270 @@
+package game
+
+import (
+	"fmt"
+
+	. "github.com/kitsunemikan/six-purrpurrs/geom"
+)
+
+// MoveNode is a move in a game tree. Its children are the alternative
+// continuations, the first of which belongs to the main line
+type MoveNode struct {
+	move     PlayerMove
+	parent   *MoveNode
+	children []*MoveNode
+
+	// selected is the index of the child that Redo continues with
+	selected int
+}
+
+// Move returns the move of the node. The root has no move
+func (n *MoveNode) Move() PlayerMove {
+	if n.parent == nil {
+		panic("move node: move: the root has no move")
+	}
+
+	return n.move
+}
+
+// Parent returns nil for the root
+func (n *MoveNode) Parent() *MoveNode {
+	return n.parent
+}
+
+// Do not modify the result
+func (n *MoveNode) Children() []*MoveNode {
+	return n.children
+}
+
+func (n *MoveNode) IsRoot() bool {
+	return n.parent == nil
+}
+
+// IsMainLine reports whether the node and each of its ancestors are the first children
+func (n *MoveNode) IsMainLine() bool {
+	for node := n; node.parent != nil; node = node.parent {
+		if node.parent.children[0] != node {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Depth returns the number of moves from the root to the node
+func (n *MoveNode) Depth() int {
+	depth := 0
+	for node := n; node.parent != nil; node = node.parent {
+		depth++
+	}
+
+	return depth
+}
+
+func (n *MoveNode) childAt(cell Offset) (int, bool) {
+	for i, child := range n.children {
+		if child.move.Cell == cell {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+// GameTree keeps every move made in a game, including the ones taken back,
+// as a tree of variations. The game is always in the position of the current
+// node, so it must not be changed other than through the tree
+type GameTree struct {
+	game    *GameState
+	root    *MoveNode
+	current *MoveNode
+}
+
+// NewGameTree makes the moves of the game its main line
+// and stays at the end of it
+func NewGameTree(g *GameState) *GameTree {
+	t := &GameTree{
+		game: g,
+		root: &MoveNode{},
+	}
+
+	t.current = t.root
+	for _, move := range g.MoveHistoryCopy() {
+		node := &MoveNode{move: move, parent: t.current}
+		t.current.children = append(t.current.children, node)
+		t.current = node
+	}
+
+	return t
+}
+
+func (t *GameTree) Game() *GameState {
+	return t.game
+}
+
+func (t *GameTree) Root() *MoveNode {
+	return t.root
+}
+
+func (t *GameTree) Current() *MoveNode {
+	return t.current
+}
+
+// Play makes a move for the player to move. A move that hasn't been made
+// in the position before starts a new variation
+func (t *GameTree) Play(pos Offset) error {
+	pos = t.game.Board.Normalize(pos)
+	if i, exists := t.current.childAt(pos); exists {
+		t.advance(i)
+		return nil
+	}
+
+	if err := t.game.Play(pos); err != nil {
+		return err
+	}
+
+	node := &MoveNode{move: t.game.Board.LatestMove(), parent: t.current}
+	t.current.children = append(t.current.children, node)
+	t.current.selected = len(t.current.children) - 1
+	t.current = node
+
+	return nil
+}
+
+// advance redoes the move of the child with the given index. The moves
+// in the tree have already been validated, so they are just marked
+func (t *GameTree) advance(child int) {
+	t.current.selected = child
+	t.current = t.current.children[child]
+
+	if err := t.game.MarkCell(t.current.move.Cell, t.current.move.Player); err != nil {
+		panic(fmt.Sprintf("game tree: advance: %v", err))
+	}
+}
+
+// Undo goes back to the parent node. The undone move is kept,
+// so it may be redone. Returns false at the root
+func (t *GameTree) Undo() bool {
+	if t.current.parent == nil {
+		return false
+	}
+
+	if err := t.game.UndoLastMove(); err != nil {
+		panic(fmt.Sprintf("game tree: undo: %v", err))
+	}
+
+	t.current = t.current.parent
+
+	return true
+}
+
+// Redo plays the move of the variation last visited from the current node,
+// or of the main line. Returns false if there are no moves after the current one
+func (t *GameTree) Redo() bool {
+	if len(t.current.children) == 0 {
+		return false
+	}
+
+	t.advance(t.current.selected)
+	return true
+}
+
+// Variations returns the alternatives to the current move,
+// including itself. Do not modify the result
+func (t *GameTree) Variations() []*MoveNode {
+	if t.current.parent == nil {
+		return []*MoveNode{t.current}
+	}
+
+	return t.current.parent.children
+}
+
+// VariationIndex returns the index of the current move among its variations
+func (t *GameTree) VariationIndex() int {
+	if t.current.parent == nil {
+		return 0
+	}
+
+	return t.current.parent.selected
+}
+
+// SwitchVariation replaces the current move with the alternative, which
+// is the given number of variations away. Returns false if there is none
+func (t *GameTree) SwitchVariation(delta int) bool {
+	i := t.VariationIndex() + delta
+	if t.current.parent == nil || i < 0 || i >= len(t.current.parent.children) {
+		return false
+	}
+
+	t.Undo()
+	t.advance(i)
+
+	return true
+}
+
+// GoTo takes the game to the position of the node, which must belong to the tree
+func (t *GameTree) GoTo(node *MoveNode) {
+	ancestors := make(map[*MoveNode]struct{})
+	for n := t.current; n != nil; n = n.parent {
+		ancestors[n] = struct{}{}
+	}
+
+	var path []*MoveNode
+	common := node
+	for ; common != nil; common = common.parent {
+		if _, found := ancestors[common]; found {
+			break
+		}
+
+		path = append(path, common)
+	}
+
+	if common == nil {
+		panic(fmt.Sprintf("game tree: go to: the node of %v doesn't belong to the tree", node.move))
+	}
+
+	for t.current != common {
+		t.Undo()
+	}
+
+	for i := len(path) - 1; i >= 0; i-- {
+		for child, n := range t.current.children {
+			if n == path[i] {
+				t.advance(child)
+				break
+			}
+		}
+	}
+}
+
+// ReturnToMainLine goes back to the move, where the current variation
+// branched off the main line
+func (t *GameTree) ReturnToMainLine() {
+	node := t.current
+	for !node.IsMainLine() {
+		node = node.parent
+	}
+
+	t.GoTo(node)
+}
+
+// MainLineEnd returns the last node of the main line
+func (t *GameTree) MainLineEnd() *MoveNode {
+	node := t.root
+	for len(node.children) > 0 {
+		node = node.children[0]
+	}
+
+	return node
+}
+
+// LineLength returns the number of moves in the current line,
+// which continues with the variations Redo would take
+func (t *GameTree) LineLength() int {
+	length := t.current.Depth()
+	for node := t.current; len(node.children) > 0; node = node.children[node.selected] {
+		length++
+	}
+
+	return length
+}
@@ -0,0 +1,132 @@
+package game_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"github.com/kitsunemikan/six-purrpurrs/game"
+	"github.com/kitsunemikan/six-purrpurrs/geom"
+)
+
+func newTreeGame() *game.GameState {
+	return game.NewGame(game.GameOptions{
+		Border:      3,
+		PlayerCount: 2,
+		Turns:       game.SingleStoneTurns,
+		Victory:     &game.EightDirStrikeVictoryChecker{VictoryLength: 3},
+	})
+}
+
+func TestGameTreeUndoRedo(t *testing.T) {
+	g := newTreeGame()
+	td.CmpNoError(t, g.Play(geom.Offset{X: 0, Y: 0}))
+	td.CmpNoError(t, g.Play(geom.Offset{X: 1, Y: 0}))
+
+	tree := game.NewGameTree(g)
+	td.CmpTrue(t, tree.Current().IsMainLine())
+	td.Cmp(t, tree.Current().Depth(), 2)
+	td.Cmp(t, tree.MainLineEnd(), tree.Current())
+
+	td.CmpTrue(t, tree.Undo())
+	td.CmpTrue(t, tree.Undo())
+	td.CmpFalse(t, tree.Undo())
+	td.Cmp(t, g.MoveNumber(), 1)
+	td.Cmp(t, tree.LineLength(), 2)
+
+	td.CmpTrue(t, tree.Redo())
+	td.CmpTrue(t, tree.Redo())
+	td.CmpFalse(t, tree.Redo())
+	td.Cmp(t, g.MoveHistoryCopy(), []game.PlayerMove{
+		{Cell: geom.Offset{X: 0, Y: 0}, Player: game.P1},
+		{Cell: geom.Offset{X: 1, Y: 0}, Player: game.P2},
+	})
+}
+
+func TestGameTreeVariations(t *testing.T) {
+	g := newTreeGame()
+	tree := game.NewGameTree(g)
+
+	td.CmpNoError(t, tree.Play(geom.Offset{X: 0, Y: 0}))
+	td.CmpNoError(t, tree.Play(geom.Offset{X: 1, Y: 0}))
+	td.CmpNoError(t, tree.Play(geom.Offset{X: 0, Y: 1}))
+	mainEnd := tree.Current()
+
+	// What if P2 had played elsewhere
+	tree.Undo()
+	tree.Undo()
+	td.CmpNoError(t, tree.Play(geom.Offset{X: 1, Y: 1}))
+	td.CmpFalse(t, tree.Current().IsMainLine())
+	td.Cmp(t, len(tree.Variations()), 2)
+	td.Cmp(t, tree.VariationIndex(), 1)
+	td.Cmp(t, tree.LineLength(), 2)
+
+	// Playing an existing move follows its variation
+	tree.Undo()
+	td.CmpNoError(t, tree.Play(geom.Offset{X: 1, Y: 0}))
+	td.Cmp(t, tree.VariationIndex(), 0)
+	td.Cmp(t, len(tree.Variations()), 2)
+	td.Cmp(t, tree.LineLength(), 3)
+
+	td.CmpTrue(t, tree.SwitchVariation(1))
+	td.Cmp(t, g.Board.LatestMove(), game.PlayerMove{Cell: geom.Offset{X: 1, Y: 1}, Player: game.P2})
+	td.CmpFalse(t, tree.SwitchVariation(1))
+
+	// Redo continues with the variation visited last
+	tree.Undo()
+	td.CmpTrue(t, tree.Redo())
+	td.Cmp(t, g.Board.LatestMove().Cell, geom.Offset{X: 1, Y: 1})
+
+	td.CmpNoError(t, tree.Play(geom.Offset{X: 2, Y: 2}))
+	branchEnd := tree.Current()
+
+	tree.ReturnToMainLine()
+	td.Cmp(t, tree.Current().Depth(), 1)
+	td.CmpTrue(t, tree.Current().IsMainLine())
+
+	tree.GoTo(mainEnd)
+	td.Cmp(t, tree.Current(), mainEnd)
+	td.Cmp(t, g.MoveHistoryCopy(), []game.PlayerMove{
+		{Cell: geom.Offset{X: 0, Y: 0}, Player: game.P1},
+		{Cell: geom.Offset{X: 1, Y: 0}, Player: game.P2},
+		{Cell: geom.Offset{X: 0, Y: 1}, Player: game.P1},
+	})
+
+	tree.GoTo(branchEnd)
+	td.Cmp(t, g.MoveHistoryCopy(), []game.PlayerMove{
+		{Cell: geom.Offset{X: 0, Y: 0}, Player: game.P1},
+		{Cell: geom.Offset{X: 1, Y: 1}, Player: game.P2},
+		{Cell: geom.Offset{X: 2, Y: 2}, Player: game.P1},
+	})
+	td.Cmp(t, tree.MainLineEnd(), mainEnd)
+
+	td.CmpPanic(t, func() { game.NewGameTree(newTreeGame()).GoTo(branchEnd) }, td.Contains("doesn't belong to the tree"))
+}
+
+func TestGameTreeInvalidMove(t *testing.T) {
+	g := newTreeGame()
+	tree := game.NewGameTree(g)
+
+	td.CmpNoError(t, tree.Play(geom.Offset{X: 0, Y: 0}))
+	if err := tree.Play(geom.Offset{X: 0, Y: 0}); !errors.Is(err, game.ErrCellOccupied) {
+		t.Errorf("got error [%v], want [%v]", err, game.ErrCellOccupied)
+	}
+
+	td.Cmp(t, len(tree.Current().Children()), 0)
+
+	// The strike of 3 wins
+	for _, cell := range []geom.Offset{{X: 0, Y: 1}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 0}} {
+		td.CmpNoError(t, tree.Play(cell))
+	}
+
+	td.CmpTrue(t, g.Over())
+	if err := tree.Play(geom.Offset{X: 3, Y: 3}); !errors.Is(err, game.ErrGameOver) {
+		t.Errorf("got error [%v], want [%v]", err, game.ErrGameOver)
+	}
+
+	tree.Undo()
+	td.CmpFalse(t, g.Over())
+	tree.Redo()
+	td.CmpTrue(t, g.Over())
+}
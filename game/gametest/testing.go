@@ -29,7 +29,7 @@ func BoardStatesEqual(got, want *game.BoardState) error {
 
 	boardDiff, same := cellBoardDiff(diffColor, got.AllCells(), want.AllCells())
 	if !same {
-		gotBoard, wantBoard := drawDiffBoards(boardModel, boardDiff, got.AllCells(), want.AllCells())
+		gotBoard, wantBoard := drawDiffBoards(boardModel, want.PlayerCount(), boardDiff, got.AllCells(), want.AllCells())
 		return fmt.Errorf("all-cell boards are different:\ngot\n%v\nwant\n%v\n", gotBoard, wantBoard)
 	}
 
@@ -37,26 +37,23 @@ func BoardStatesEqual(got, want *game.BoardState) error {
 	wantUnoccupied := cellSetToCellBoard(want.UnoccupiedCells(), game.CellUnoccupied)
 	boardDiff, same = cellBoardDiff(diffColor, gotUnoccupied, wantUnoccupied)
 	if !same {
-		gotBoard, wantBoard := drawDiffBoards(boardModel, boardDiff, gotUnoccupied, wantUnoccupied)
+		gotBoard, wantBoard := drawDiffBoards(boardModel, want.PlayerCount(), boardDiff, gotUnoccupied, wantUnoccupied)
 		return fmt.Errorf("unoccupied cell boards are different:\ngot\n%v\nwant\n%v\n", gotBoard, wantBoard)
 	}
 
-	gotCellsP1 := cellSetToCellBoard(got.PlayerCells()[0], game.CellP1)
-	wantCellsP1 := cellSetToCellBoard(want.PlayerCells()[0], game.CellP1)
-
-	boardDiff, same = cellBoardDiff(diffColor, gotCellsP1, wantCellsP1)
-	if !same {
-		gotBoard, wantBoard := drawDiffBoards(boardModel, boardDiff, gotCellsP1, wantCellsP1)
-		return fmt.Errorf("P1 cell boards are different:\ngot\n%v\nwant\n%v\n", gotBoard, wantBoard)
+	if got.PlayerCount() != want.PlayerCount() {
+		return fmt.Errorf("got player count %v, want %v", got.PlayerCount(), want.PlayerCount())
 	}
 
-	gotCellsP2 := cellSetToCellBoard(got.PlayerCells()[1], game.CellP2)
-	wantCellsP2 := cellSetToCellBoard(want.PlayerCells()[1], game.CellP2)
+	for player := range want.PlayerCells() {
+		gotCells := cellSetToCellBoard(got.PlayerCells()[player], game.CellState(player))
+		wantCells := cellSetToCellBoard(want.PlayerCells()[player], game.CellState(player))
 
-	boardDiff, same = cellBoardDiff(diffColor, gotCellsP2, wantCellsP2)
-	if !same {
-		gotBoard, wantBoard := drawDiffBoards(boardModel, boardDiff, gotCellsP2, wantCellsP2)
-		return fmt.Errorf("P2 cell boards are different:\ngot\n%v\nwant\n%v\n", gotBoard, wantBoard)
+		boardDiff, same = cellBoardDiff(diffColor, gotCells, wantCells)
+		if !same {
+			gotBoard, wantBoard := drawDiffBoards(boardModel, want.PlayerCount(), boardDiff, gotCells, wantCells)
+			return fmt.Errorf("%v cell boards are different:\ngot\n%v\nwant\n%v\n", game.PlayerID(player), gotBoard, wantBoard)
+		}
 	}
 
 	gotDelta := got.Delta()
@@ -71,6 +68,10 @@ func BoardStatesEqual(got, want *game.BoardState) error {
 		return fmt.Errorf("deltas are different:\ngot\n%v\nwant\n%v\n", litter.Sdump(gotHistory), litter.Sdump(wantHistory))
 	}
 
+	if got.Hash() != want.Hash() {
+		return fmt.Errorf("got hash %#x, want %#x", got.Hash(), want.Hash())
+	}
+
 	if !got.BoardBound().IsEqual(want.BoardBound()) {
 		return fmt.Errorf("board bounds are different:\ngot\n%v\nwant\n%v\n", litter.Sdump(got.BoardBound()), litter.Sdump(want.BoardBound()))
 	}
@@ -114,14 +115,14 @@ func cellSetToCellBoard(set map[Offset]struct{}, state game.CellState) map[Offse
 	return board
 }
 
-func drawDiffBoards(model gamecli.BoardModel, diff map[Offset]lipgloss.Style, got, want map[Offset]game.CellState) (string, string) {
+func drawDiffBoards(model gamecli.BoardModel, playerCount int, diff map[Offset]lipgloss.Style, got, want map[Offset]game.CellState) (string, string) {
 	model.ForcedHighlight = diff
 
-	gotBoard := game.NewBoardStateFromCells(1, got)
+	gotBoard := game.NewBoardStateFromCells(1, playerCount, got)
 	model.Board = gotBoard
 	gotStr := model.CenterOnBoard().View()
 
-	wantBoard := game.NewBoardStateFromCells(1, want)
+	wantBoard := game.NewBoardStateFromCells(1, playerCount, want)
 	model.Board = wantBoard
 	wantStr := model.CenterOnBoard().View()
 
@@ -0,0 +1,207 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/kitsunemikan/six-purrpurrs/geom"
+)
+
+// unmarshalEnum finds the value whose String is the text
+func unmarshalEnum[T fmt.Stringer](text []byte, values ...T) (T, error) {
+	for _, value := range values {
+		if value.String() == string(text) {
+			return value, nil
+		}
+	}
+
+	var zero T
+	return zero, fmt.Errorf("unknown %T %q", zero, text)
+}
+
+func (t Topology) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *Topology) UnmarshalText(text []byte) (err error) {
+	*t, err = unmarshalEnum(text, SquareTopology, HexTopology)
+	return
+}
+
+func (op OverlinePolicy) MarshalText() ([]byte, error) {
+	return []byte(op.String()), nil
+}
+
+func (op *OverlinePolicy) UnmarshalText(text []byte) (err error) {
+	*op, err = unmarshalEnum(text, OverlineAllowed, OverlineNotCounted, OverlineLoses)
+	return
+}
+
+func (o Outcome) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+func (o *Outcome) UnmarshalText(text []byte) (err error) {
+	*o, err = unmarshalEnum(text, OutcomeOngoing, OutcomeStrike, OutcomeResignation, OutcomeTimeout,
+		OutcomeAgreement, OutcomeBoardFull, OutcomeMoveLimit, OutcomeNoWinningWindow)
+	return
+}
+
+func (op OpeningProtocol) MarshalText() ([]byte, error) {
+	return []byte(op.String()), nil
+}
+
+func (op *OpeningProtocol) UnmarshalText(text []byte) (err error) {
+	*op, err = unmarshalEnum(text, NoOpening, PieRule, SwapOpening, Swap2Opening)
+	return
+}
+
+func (oc OpeningChoice) MarshalText() ([]byte, error) {
+	return []byte(oc.String()), nil
+}
+
+func (oc *OpeningChoice) UnmarshalText(text []byte) (err error) {
+	*oc, err = unmarshalEnum(text, OpeningKeepColor, OpeningSwapColor, OpeningPlaceMore)
+	return
+}
+
+// Names of the rules and victory checkers in JSON
+const (
+	renjuRulesName = "renju"
+
+	eightDirVictoryName = "strike"
+	exactVictoryName    = "exact"
+)
+
+type turnsJSON struct {
+	FirstTurnStones int `json:"firstTurnStones"`
+	StonesPerTurn   int `json:"stonesPerTurn"`
+}
+
+type rulesJSON struct {
+	Type       string   `json:"type"`
+	Restricted PlayerID `json:"restricted"`
+}
+
+type victoryJSON struct {
+	Type     string          `json:"type"`
+	Length   int             `json:"length"`
+	Overline *OverlinePolicy `json:"overline,omitempty"`
+}
+
+type gameOptionsJSON struct {
+	Border             int          `json:"border"`
+	Topology           Topology     `json:"topology"`
+	BoardSize          geom.Offset  `json:"boardSize"`
+	Wrap               bool         `json:"wrap,omitempty"`
+	Layout             *Layout      `json:"layout,omitempty"`
+	PlayerCount        int          `json:"playerCount"`
+	Turns              *turnsJSON   `json:"turns,omitempty"`
+	Rules              *rulesJSON   `json:"rules,omitempty"`
+	Victory            *victoryJSON `json:"victory"`
+	MoveLimit          int          `json:"moveLimit,omitempty"`
+	DrawWithoutWindows bool         `json:"drawWithoutWindows,omitempty"`
+}
+
+// MarshalJSON supports only the turn policies, move rules
+// and victory checkers of this package
+func (opts GameOptions) MarshalJSON() ([]byte, error) {
+	data := gameOptionsJSON{
+		Border:             opts.Border,
+		Topology:           opts.Topology,
+		BoardSize:          opts.BoardSize,
+		Wrap:               opts.Wrap,
+		Layout:             opts.Layout,
+		PlayerCount:        opts.PlayerCount,
+		MoveLimit:          opts.MoveLimit,
+		DrawWithoutWindows: opts.DrawWithoutWindows,
+	}
+
+	switch turns := opts.Turns.(type) {
+	case nil:
+	case MultiStoneTurnPolicy:
+		data.Turns = &turnsJSON{FirstTurnStones: turns.FirstTurnStones, StonesPerTurn: turns.StonesPerTurn}
+	default:
+		return nil, fmt.Errorf("marshal game options: unsupported turn policy %T", opts.Turns)
+	}
+
+	switch rules := opts.Rules.(type) {
+	case nil:
+	case RenjuRules:
+		data.Rules = &rulesJSON{Type: renjuRulesName, Restricted: rules.Restricted}
+	default:
+		return nil, fmt.Errorf("marshal game options: unsupported move rules %T", opts.Rules)
+	}
+
+	switch victory := opts.Victory.(type) {
+	case *EightDirStrikeVictoryChecker:
+		data.Victory = &victoryJSON{Type: eightDirVictoryName, Length: victory.VictoryLength}
+	case *ExactStrikeVictoryChecker:
+		overline := victory.Overline
+		data.Victory = &victoryJSON{Type: exactVictoryName, Length: victory.VictoryLength, Overline: &overline}
+	default:
+		return nil, fmt.Errorf("marshal game options: unsupported victory checker %T", opts.Victory)
+	}
+
+	return json.Marshal(data)
+}
+
+func (opts *GameOptions) UnmarshalJSON(b []byte) error {
+	var data gameOptionsJSON
+	if err := json.Unmarshal(b, &data); err != nil {
+		return fmt.Errorf("unmarshal game options: %w", err)
+	}
+
+	*opts = GameOptions{
+		Border:             data.Border,
+		Topology:           data.Topology,
+		BoardSize:          data.BoardSize,
+		Wrap:               data.Wrap,
+		Layout:             data.Layout,
+		PlayerCount:        data.PlayerCount,
+		MoveLimit:          data.MoveLimit,
+		DrawWithoutWindows: data.DrawWithoutWindows,
+	}
+
+	if data.Turns != nil {
+		if data.Turns.FirstTurnStones < 1 || data.Turns.StonesPerTurn < 1 {
+			return fmt.Errorf("unmarshal game options: players must place at least one stone per turn (turns=%+v)", *data.Turns)
+		}
+
+		opts.Turns = MultiStoneTurnPolicy{FirstTurnStones: data.Turns.FirstTurnStones, StonesPerTurn: data.Turns.StonesPerTurn}
+	}
+
+	if data.Rules != nil {
+		if data.Rules.Type != renjuRulesName {
+			return fmt.Errorf("unmarshal game options: unknown move rules %q", data.Rules.Type)
+		}
+
+		opts.Rules = RenjuRules{Restricted: data.Rules.Restricted}
+	}
+
+	if data.Victory == nil {
+		return fmt.Errorf("unmarshal game options: no victory checker")
+	}
+
+	switch data.Victory.Type {
+	case eightDirVictoryName:
+		opts.Victory = &EightDirStrikeVictoryChecker{VictoryLength: data.Victory.Length}
+
+	case exactVictoryName:
+		overline := OverlineAllowed
+		if data.Victory.Overline != nil {
+			overline = *data.Victory.Overline
+		}
+
+		opts.Victory = &ExactStrikeVictoryChecker{
+			VictoryLength: data.Victory.Length,
+			Overline:      overline,
+			PlayerCount:   data.PlayerCount,
+		}
+
+	default:
+		return fmt.Errorf("unmarshal game options: unknown victory checker %q", data.Victory.Type)
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package game_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"github.com/kitsunemikan/six-purrpurrs/game"
+	"github.com/kitsunemikan/six-purrpurrs/geom"
+)
+
+func TestGameOptionsJSON(t *testing.T) {
+	layout, err := game.ParseLayout(strings.NewReader("..#\n . \n##."))
+	td.CmpNoError(t, err)
+
+	cases := []struct {
+		desc    string
+		options game.GameOptions
+	}{
+		{
+			"defaults",
+			game.GameOptions{
+				Border:      7,
+				PlayerCount: 2,
+				Victory:     &game.EightDirStrikeVictoryChecker{VictoryLength: 6},
+			},
+		},
+		{
+			"everything",
+			game.GameOptions{
+				Topology:           game.HexTopology,
+				BoardSize:          geom.Offset{X: 3, Y: 3},
+				Wrap:               true,
+				Layout:             layout,
+				PlayerCount:        2,
+				Turns:              game.Connect6Turns,
+				Rules:              game.RenjuRules{Restricted: game.P1},
+				Victory:            &game.ExactStrikeVictoryChecker{VictoryLength: 5, Overline: game.OverlineLoses, PlayerCount: 2},
+				MoveLimit:          100,
+				DrawWithoutWindows: true,
+			},
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.desc, func(t *testing.T) {
+			data, err := json.Marshal(test.options)
+			td.CmpNoError(t, err)
+
+			var got game.GameOptions
+			td.CmpNoError(t, json.Unmarshal(data, &got))
+
+			if test.options.Layout != nil {
+				td.Cmp(t, got.Layout.String(), test.options.Layout.String())
+				got.Layout, test.options.Layout = nil, nil
+			}
+
+			td.Cmp(t, got, test.options)
+		})
+	}
+}
+
+func TestGameOptionsJSONErrors(t *testing.T) {
+	cases := []string{
+		`{"playerCount": 2}`,
+		`{"playerCount": 2, "victory": {"type": "lines", "length": 5}}`,
+		`{"playerCount": 2, "topology": "triangle", "victory": {"type": "strike", "length": 5}}`,
+		`{"playerCount": 2, "rules": {"type": "gomoku"}, "victory": {"type": "strike", "length": 5}}`,
+		`{"playerCount": 2, "turns": {"firstTurnStones": 0, "stonesPerTurn": 2}, "victory": {"type": "strike", "length": 5}}`,
+		`{"playerCount": 2, "layout": "..x", "victory": {"type": "strike", "length": 5}}`,
+	}
+
+	for _, data := range cases {
+		var got game.GameOptions
+		td.CmpError(t, json.Unmarshal([]byte(data), &got), data)
+	}
+}
+
+func TestGameOptionsValidate(t *testing.T) {
+	cases := []string{
+		`{"playerCount": 1, "victory": {"type": "strike", "length": 5}}`,
+		`{"playerCount": 2, "victory": {"type": "strike", "length": 0}}`,
+		`{"playerCount": 2, "wrap": true, "victory": {"type": "strike", "length": 5}}`,
+		`{"playerCount": 2, "boardSize": {"X": 3, "Y": 3}, "layout": "..\n..", "victory": {"type": "strike", "length": 5}}`,
+		`{"playerCount": 2, "boardSize": {"X": -3, "Y": 3}, "victory": {"type": "strike", "length": 5}}`,
+		`{"playerCount": 2, "moveLimit": -1, "victory": {"type": "strike", "length": 5}}`,
+		`{"playerCount": 2, "border": -1, "victory": {"type": "strike", "length": 5}}`,
+		`{"playerCount": 2, "rules": {"type": "renju", "restricted": 2}, "victory": {"type": "strike", "length": 5}}`,
+	}
+
+	for _, data := range cases {
+		var got game.GameOptions
+		td.CmpNoError(t, json.Unmarshal([]byte(data), &got), data)
+		td.CmpError(t, got.Validate(), data)
+	}
+
+	options := game.GameOptions{
+		PlayerCount: 2,
+		Topology:    game.Topology(7),
+		Victory:     &game.EightDirStrikeVictoryChecker{VictoryLength: 5},
+	}
+	td.CmpError(t, options.Validate())
+
+	options.Topology = game.HexTopology
+	td.CmpNoError(t, options.Validate())
+}
+
+func TestLayoutString(t *testing.T) {
+	text := "..#\n . \n##."
+
+	layout, err := game.ParseLayout(strings.NewReader(text))
+	td.CmpNoError(t, err)
+	td.Cmp(t, layout.String(), text)
+}
@@ -0,0 +1,126 @@
+package game
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kitsunemikan/six-purrpurrs/geom"
+)
+
+// Layout is a starting shape of a bounded board. Cells are addressed
+// relative to the top-left corner of the layout
+type Layout struct {
+	Size geom.Offset
+
+	// blocked and unavailable cells, all the others are unoccupied
+	cells   map[geom.Offset]CellState
+	rowLens []int
+}
+
+const (
+	layoutUnoccupied  = '.'
+	layoutBlocked     = '#'
+	layoutUnavailable = ' '
+)
+
+// ParseLayout reads a layout, where every line is a row of the board:
+//   - '.' is an unoccupied cell
+//   - '#' is a blocked cell, i.e., a rock
+//   - ' ' is an unavailable cell, i.e., a hole in the board
+//
+// Rows shorter than the longest one are padded with unavailable cells
+func ParseLayout(r io.Reader) (*Layout, error) {
+	layout := &Layout{cells: make(map[geom.Offset]CellState)}
+
+	scanner := bufio.NewScanner(r)
+	for y := 0; scanner.Scan(); y++ {
+		line := []rune(scanner.Text())
+
+		for x, ch := range line {
+			cell := geom.Offset{X: x, Y: y}
+
+			switch ch {
+			case layoutUnoccupied:
+			case layoutBlocked:
+				layout.cells[cell] = CellBlocked
+			case layoutUnavailable:
+				layout.cells[cell] = CellUnavailable
+			default:
+				return nil, fmt.Errorf("parse layout: line %d, column %d: unknown cell %q", y+1, x+1, ch)
+			}
+		}
+
+		layout.rowLens = append(layout.rowLens, len(line))
+		if len(line) > layout.Size.X {
+			layout.Size.X = len(line)
+		}
+
+		layout.Size.Y = y + 1
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("parse layout: %w", err)
+	}
+
+	if layout.Size.X == 0 || layout.Size.Y == 0 {
+		return nil, errors.New("parse layout: the layout is empty")
+	}
+
+	return layout, nil
+}
+
+// Cell returns the state of the layout cell. Cells outside
+// of the layout or past the end of their row are unavailable
+func (l *Layout) Cell(pos geom.Offset) CellState {
+	if !pos.IsInsideRect(geom.NewRectFromOffsets(geom.Offset{}, l.Size)) || pos.X >= l.rowLens[pos.Y] {
+		return CellUnavailable
+	}
+
+	state, ok := l.cells[pos]
+	if !ok {
+		return CellUnoccupied
+	}
+
+	return state
+}
+
+// String returns the layout in the format read by ParseLayout
+func (l *Layout) String() string {
+	var text strings.Builder
+	for y, rowLen := range l.rowLens {
+		if y > 0 {
+			text.WriteByte('\n')
+		}
+
+		for x := 0; x < rowLen; x++ {
+			switch l.Cell(geom.Offset{X: x, Y: y}) {
+			case CellBlocked:
+				text.WriteRune(layoutBlocked)
+			case CellUnavailable:
+				text.WriteRune(layoutUnavailable)
+			default:
+				text.WriteRune(layoutUnoccupied)
+			}
+		}
+	}
+
+	return text.String()
+}
+
+func (l *Layout) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+func (l *Layout) UnmarshalText(text []byte) error {
+	parsed, err := ParseLayout(bytes.NewReader(text))
+	if err != nil {
+		return err
+	}
+
+	*l = *parsed
+	return nil
+}
@@ -0,0 +1,63 @@
+package game_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kitsunemikan/six-purrpurrs/game"
+	"github.com/kitsunemikan/six-purrpurrs/geom"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestParseLayout(t *testing.T) {
+	layout, err := game.ParseLayout(strings.NewReader("..#\n. .\n.\n"))
+	td.CmpNoError(t, err)
+
+	td.Cmp(t, layout.Size, geom.Offset{X: 3, Y: 3})
+
+	td.Cmp(t, layout.Cell(geom.Offset{X: 0, Y: 0}), game.CellUnoccupied)
+	td.Cmp(t, layout.Cell(geom.Offset{X: 2, Y: 0}), game.CellBlocked)
+	td.Cmp(t, layout.Cell(geom.Offset{X: 1, Y: 1}), game.CellUnavailable)
+
+	// Short rows are padded with holes
+	td.Cmp(t, layout.Cell(geom.Offset{X: 1, Y: 2}), game.CellUnavailable)
+	td.Cmp(t, layout.Cell(geom.Offset{X: 3, Y: 0}), game.CellUnavailable)
+}
+
+func TestParseLayoutErrors(t *testing.T) {
+	_, err := game.ParseLayout(strings.NewReader("...\n.X.\n"))
+	td.Cmp(t, err, td.String(`parse layout: line 2, column 2: unknown cell 'X'`))
+
+	_, err = game.ParseLayout(strings.NewReader(""))
+	td.CmpError(t, err)
+}
+
+func TestGameWithLayout(t *testing.T) {
+	layout, err := game.ParseLayout(strings.NewReader(".#.\n...\n. ."))
+	td.CmpNoError(t, err)
+
+	g := game.NewGame(game.GameOptions{
+		PlayerCount: 2,
+		Layout:      layout,
+		Victory:     &game.EightDirStrikeVictoryChecker{VictoryLength: 3},
+	})
+
+	td.Cmp(t, g.BoardBound(), geom.Rect{X: -1, Y: -1, W: 3, H: 3})
+	td.Cmp(t, g.Cell(geom.Offset{X: 0, Y: -1}), game.CellBlocked)
+	td.Cmp(t, g.Cell(geom.Offset{X: 0, Y: 1}), game.CellUnavailable)
+	td.Cmp(t, len(g.Board.UnoccupiedCells()), 7)
+
+	err = g.MarkCell(geom.Offset{X: 0, Y: -1}, game.P1)
+	if !errors.Is(err, game.ErrCellUnavailable) {
+		t.Errorf("marking a rock: got error [%v], want [%v]", err, game.ErrCellUnavailable)
+	}
+
+	// The rock breaks the top row
+	g.MarkCell(geom.Offset{X: -1, Y: -1}, game.P1)
+	g.MarkCell(geom.Offset{X: 1, Y: -1}, game.P1)
+	td.CmpFalse(t, g.Over())
+
+	strike := g.StrikeStat.StrikesThrough(geom.Offset{X: -1, Y: -1})[game.StrikeRight.FixedID]
+	td.CmpFalse(t, strike.ExtendableAfter)
+}
@@ -0,0 +1,44 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/kitsunemikan/six-purrpurrs/geom"
+)
+
+// MoveRules restrict the unoccupied cells where players may place their stones
+type MoveRules interface {
+	// CheckMove returns a *ForbiddenMoveError, if the player may not place a stone at pos
+	CheckMove(strikes *StrikeSet, victoryLength int, pos geom.Offset, player PlayerID) error
+}
+
+type ForbiddenReason int
+
+const (
+	ForbiddenDoubleThree ForbiddenReason = iota
+	ForbiddenDoubleFour
+	ForbiddenOverline
+)
+
+func (r ForbiddenReason) String() string {
+	switch r {
+	case ForbiddenDoubleThree:
+		return "double-three"
+	case ForbiddenDoubleFour:
+		return "double-four"
+	case ForbiddenOverline:
+		return "overline"
+	}
+
+	return fmt.Sprintf("UnknownForbiddenReason%d", int(r))
+}
+
+type ForbiddenMoveError struct {
+	Cell   geom.Offset
+	Player PlayerID
+	Reason ForbiddenReason
+}
+
+func (e *ForbiddenMoveError) Error() string {
+	return fmt.Sprintf("move by %v at %v is forbidden: %v", e.Player, e.Cell, e.Reason)
+}
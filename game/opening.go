@@ -0,0 +1,219 @@
+package game
+
+import (
+	"fmt"
+)
+
+// OpeningProtocol describes how a two-player game starts, so that the first player
+// doesn't get the full first move advantage
+type OpeningProtocol int
+
+const (
+	// NoOpening starts the game right away
+	NoOpening OpeningProtocol = iota
+
+	// PieRule lets the first player place one stone, and then
+	// the second player chooses which colour to play
+	PieRule
+
+	// SwapOpening lets the first player place three stones, and then
+	// the second player chooses which colour to play
+	SwapOpening
+
+	// Swap2Opening lets the first player place three stones, and then the second player
+	// either chooses which colour to play, or places two more stones and lets
+	// the first player choose the colour instead
+	Swap2Opening
+)
+
+func (op OpeningProtocol) String() string {
+	switch op {
+	case NoOpening:
+		return "none"
+	case PieRule:
+		return "pie"
+	case SwapOpening:
+		return "swap"
+	case Swap2Opening:
+		return "swap2"
+	}
+
+	return fmt.Sprintf("UnknownOpeningProtocol%d", int(op))
+}
+
+type OpeningChoice int
+
+const (
+	// OpeningKeepColor keeps the colours the players currently have
+	OpeningKeepColor OpeningChoice = iota
+
+	// OpeningSwapColor swaps colours between players
+	OpeningSwapColor
+
+	// OpeningPlaceMore lets the deciding player place two more stones
+	// and pass the decision to the opponent
+	OpeningPlaceMore
+)
+
+func (oc OpeningChoice) String() string {
+	switch oc {
+	case OpeningKeepColor:
+		return "keep"
+	case OpeningSwapColor:
+		return "swap"
+	case OpeningPlaceMore:
+		return "place more"
+	}
+
+	return fmt.Sprintf("UnknownOpeningChoice%d", int(oc))
+}
+
+type OpeningStepKind int
+
+const (
+	OpeningDone OpeningStepKind = iota
+	OpeningPlaceStone
+	OpeningDecide
+)
+
+// OpeningStep is an action one of the players must do during the opening.
+// The stones are placed as regular moves, so their colour is decided by the game
+// turn policy, regardless of who places them.
+type OpeningStep struct {
+	Kind OpeningStepKind
+	Seat int
+
+	// Choices are available to the deciding player for OpeningDecide steps
+	Choices []OpeningChoice
+}
+
+// Opening tracks the progress of an opening protocol. Players are identified by seats,
+// which are their initial indices, e.g., seat 0 is the tentative first player.
+// Each seat plays the colour with the same PlayerID, unless colours were swapped.
+type Opening struct {
+	protocol OpeningProtocol
+	steps    []OpeningStep
+	swapped  bool
+
+	decisions []OpeningChoice
+}
+
+func placeSteps(seat, count int) []OpeningStep {
+	steps := make([]OpeningStep, count)
+	for i := range steps {
+		steps[i] = OpeningStep{Kind: OpeningPlaceStone, Seat: seat}
+	}
+
+	return steps
+}
+
+func decideStep(seat int, choices ...OpeningChoice) OpeningStep {
+	return OpeningStep{Kind: OpeningDecide, Seat: seat, Choices: choices}
+}
+
+func NewOpening(protocol OpeningProtocol) *Opening {
+	o := &Opening{protocol: protocol}
+
+	switch protocol {
+	case NoOpening:
+
+	case PieRule:
+		o.steps = append(placeSteps(0, 1), decideStep(1, OpeningKeepColor, OpeningSwapColor))
+
+	case SwapOpening:
+		o.steps = append(placeSteps(0, 3), decideStep(1, OpeningKeepColor, OpeningSwapColor))
+
+	case Swap2Opening:
+		o.steps = append(placeSteps(0, 3), decideStep(1, OpeningKeepColor, OpeningSwapColor, OpeningPlaceMore))
+
+	default:
+		panic(fmt.Sprintf("new opening: unknown protocol %v", protocol))
+	}
+
+	return o
+}
+
+func (o *Opening) Protocol() OpeningProtocol {
+	return o.protocol
+}
+
+func (o *Opening) Step() OpeningStep {
+	if len(o.steps) == 0 {
+		return OpeningStep{Kind: OpeningDone}
+	}
+
+	return o.steps[0]
+}
+
+func (o *Opening) Done() bool {
+	return len(o.steps) == 0
+}
+
+// StonePlaced advances the opening after the current seat has placed a stone
+func (o *Opening) StonePlaced() {
+	if o.Step().Kind != OpeningPlaceStone {
+		panic(fmt.Sprintf("opening: stone placed: no stone was expected (step kind=%d)", o.Step().Kind))
+	}
+
+	o.steps = o.steps[1:]
+}
+
+// Decide advances the opening with a choice made by the current seat
+func (o *Opening) Decide(choice OpeningChoice) error {
+	step := o.Step()
+	if step.Kind != OpeningDecide {
+		return fmt.Errorf("opening: decide %v: no decision was expected (step kind=%d)", choice, step.Kind)
+	}
+
+	available := false
+	for _, c := range step.Choices {
+		if c == choice {
+			available = true
+			break
+		}
+	}
+
+	if !available {
+		return fmt.Errorf("opening: decide %v: choice is unavailable (choices=%v)", choice, step.Choices)
+	}
+
+	o.steps = o.steps[1:]
+	o.decisions = append(o.decisions, choice)
+
+	switch choice {
+	case OpeningSwapColor:
+		o.swapped = !o.swapped
+
+	case OpeningPlaceMore:
+		opponent := 1 - step.Seat
+		more := append(placeSteps(step.Seat, 2), decideStep(opponent, OpeningKeepColor, OpeningSwapColor))
+		o.steps = append(more, o.steps...)
+	}
+
+	return nil
+}
+
+// Decisions returns the choices made so far, in order
+func (o *Opening) Decisions() []OpeningChoice {
+	decisions := make([]OpeningChoice, len(o.decisions))
+	copy(decisions, o.decisions)
+	return decisions
+}
+
+// PlayerOf returns the colour played by the seat
+func (o *Opening) PlayerOf(seat int) PlayerID {
+	if o.swapped {
+		return PlayerID(1 - seat)
+	}
+
+	return PlayerID(seat)
+}
+
+// SeatOf returns the seat playing the colour
+func (o *Opening) SeatOf(player PlayerID) int {
+	if o.swapped {
+		return 1 - int(player)
+	}
+
+	return int(player)
+}
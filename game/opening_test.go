@@ -0,0 +1,116 @@
+package game_test
+
+import (
+	"testing"
+
+	"github.com/kitsunemikan/six-purrpurrs/game"
+)
+
+func TestOpening(t *testing.T) {
+	type action struct {
+		seat   int
+		place  bool
+		choice game.OpeningChoice
+	}
+
+	place := func(seat int) action {
+		return action{seat: seat, place: true}
+	}
+
+	decide := func(seat int, choice game.OpeningChoice) action {
+		return action{seat: seat, choice: choice}
+	}
+
+	cases := []struct {
+		desc        string
+		protocol    game.OpeningProtocol
+		actions     []action
+		wantSwapped bool
+	}{
+		{
+			"no opening is done right away",
+			game.NoOpening,
+			nil,
+			false,
+		},
+		{
+			"pie rule with a swap",
+			game.PieRule,
+			[]action{place(0), decide(1, game.OpeningSwapColor)},
+			true,
+		},
+		{
+			"swap keeping colours",
+			game.SwapOpening,
+			[]action{place(0), place(0), place(0), decide(1, game.OpeningKeepColor)},
+			false,
+		},
+		{
+			"swap2 with a swap right away",
+			game.Swap2Opening,
+			[]action{place(0), place(0), place(0), decide(1, game.OpeningSwapColor)},
+			true,
+		},
+		{
+			"swap2 placing more stones and swapping back",
+			game.Swap2Opening,
+			[]action{
+				place(0), place(0), place(0),
+				decide(1, game.OpeningPlaceMore),
+				place(1), place(1),
+				decide(0, game.OpeningSwapColor),
+			},
+			true,
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.desc, func(t *testing.T) {
+			opening := game.NewOpening(test.protocol)
+
+			for i, action := range test.actions {
+				step := opening.Step()
+				if step.Seat != action.seat {
+					t.Fatalf("step %d: got seat %d, want %d", i, step.Seat, action.seat)
+				}
+
+				if action.place {
+					if step.Kind != game.OpeningPlaceStone {
+						t.Fatalf("step %d: got step kind %v, want stone placement", i, step.Kind)
+					}
+
+					opening.StonePlaced()
+					continue
+				}
+
+				if err := opening.Decide(action.choice); err != nil {
+					t.Fatalf("step %d: got error [%v], want none", i, err)
+				}
+			}
+
+			if !opening.Done() {
+				t.Fatalf("opening is not done, next step: %+v", opening.Step())
+			}
+
+			gotSwapped := opening.PlayerOf(0) == game.P2
+			if gotSwapped != test.wantSwapped {
+				t.Errorf("got colours swapped %v, want %v", gotSwapped, test.wantSwapped)
+			}
+
+			if opening.SeatOf(opening.PlayerOf(1)) != 1 {
+				t.Errorf("seat of player of seat 1 is %d, want 1", opening.SeatOf(opening.PlayerOf(1)))
+			}
+		})
+	}
+
+	t.Run("unavailable choice is rejected", func(t *testing.T) {
+		opening := game.NewOpening(game.SwapOpening)
+		opening.StonePlaced()
+		opening.StonePlaced()
+		opening.StonePlaced()
+
+		if err := opening.Decide(game.OpeningPlaceMore); err == nil {
+			t.Errorf("got no error, want one")
+		}
+	})
+}
@@ -1,9 +1,113 @@
 package game
 
 import (
+	"context"
+
 	. "github.com/kitsunemikan/six-purrpurrs/geom"
 )
 
+// PlayerAgent chooses moves for a player. Agents see the game through
+// a snapshot, so they can't change it, see View
 type PlayerAgent interface {
-	MakeMove(*GameState) Offset
+	MakeMove(View) Offset
+}
+
+// ContextAgent is a player agent, whose moves can be cancelled. Once the context
+// is done, the agent should give up and return the error of the context
+type ContextAgent interface {
+	MakeMoveContext(ctx context.Context, g View) (Offset, error)
+}
+
+// WithContext adapts the agent to ContextAgent. Agents that don't implement it
+// keep thinking after the context is done, but their move is then dropped
+func WithContext(agent PlayerAgent) ContextAgent {
+	if contextAgent, ok := agent.(ContextAgent); ok {
+		return contextAgent
+	}
+
+	return contextAdapter{agent}
+}
+
+type contextAdapter struct {
+	agent PlayerAgent
+}
+
+func (a contextAdapter) MakeMoveContext(ctx context.Context, g View) (Offset, error) {
+	if err := ctx.Err(); err != nil {
+		return Offset{}, err
+	}
+
+	// Buffered, so the agent doesn't block forever, if the move is dropped
+	moves := make(chan Offset, 1)
+	go func() {
+		moves <- a.agent.MakeMove(g)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return Offset{}, ctx.Err()
+	case move := <-moves:
+		return move, nil
+	}
+}
+
+// OpeningDecider is implemented by player agents that can make decisions
+// during opening protocols. Agents that don't implement it always keep their colour.
+// The me argument is the colour the agent plays at the moment of the decision.
+type OpeningDecider interface {
+	DecideOpening(g View, me PlayerID, choices []OpeningChoice) OpeningChoice
+}
+
+// ContextOpeningDecider is an opening decider, whose decisions can be cancelled,
+// see ContextAgent
+type ContextOpeningDecider interface {
+	DecideOpeningContext(ctx context.Context, g View, me PlayerID, choices []OpeningChoice) (OpeningChoice, error)
+}
+
+// DrawResponder is implemented by player agents that can respond to draw offers.
+// Agents that don't implement it decline all the offers. The me argument is
+// the colour the agent plays
+type DrawResponder interface {
+	RespondToDraw(g View, me, offeredBy PlayerID) bool
+}
+
+// ContextDrawResponder is a draw responder, whose responses can be cancelled,
+// see ContextAgent
+type ContextDrawResponder interface {
+	RespondToDrawContext(ctx context.Context, g View, me, offeredBy PlayerID) (bool, error)
+}
+
+// GameFollower is implemented by player agents that keep their own copy of the
+// game in sync. StartGame is called before the first move is awaited, then
+// FollowGame receives the events of the game, including the GameOverEvent.
+// Events are sent while the agent may be making a move, so they must not block
+type GameFollower interface {
+	StartGame(g View)
+	FollowGame(event GameEvent)
+}
+
+// StartFollowing starts the game for the agents that are game followers,
+// and passes them the events of the game, until stopped
+func StartFollowing(g *GameState, agents []PlayerAgent) (stop func()) {
+	var followers []GameFollower
+	for _, agent := range agents {
+		if follower, ok := agent.(GameFollower); ok {
+			followers = append(followers, follower)
+		}
+	}
+
+	if len(followers) == 0 {
+		return func() {}
+	}
+
+	view := g.Snapshot()
+	for _, follower := range followers {
+		follower.StartGame(view)
+	}
+
+	return g.Subscribe(func(event GameEvent) {
+		for _, follower := range followers {
+			follower.FollowGame(event)
+		}
+	})
 }
@@ -0,0 +1,177 @@
+package game_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"github.com/kitsunemikan/six-purrpurrs/ai"
+	"github.com/kitsunemikan/six-purrpurrs/game"
+	"github.com/kitsunemikan/six-purrpurrs/geom"
+)
+
+// blockingAgent makes its move only once released
+type blockingAgent struct {
+	release chan struct{}
+}
+
+func (a blockingAgent) MakeMove(game.View) geom.Offset {
+	<-a.release
+	return geom.Offset{X: 1, Y: 2}
+}
+
+func TestWithContext(t *testing.T) {
+	g := game.NewGame(game.GameOptions{
+		Border:      2,
+		PlayerCount: 2,
+		Victory:     &game.EightDirStrikeVictoryChecker{VictoryLength: 6},
+	})
+
+	agent := blockingAgent{make(chan struct{})}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := game.WithContext(agent).MakeMoveContext(ctx, g.Snapshot())
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got error [%v], want [%v]", err, context.Canceled)
+	}
+
+	close(agent.release)
+	move, err := game.WithContext(agent).MakeMoveContext(context.Background(), g.Snapshot())
+	td.CmpNoError(t, err)
+	td.Cmp(t, move, geom.Offset{X: 1, Y: 2})
+
+	// Context agents are used as is
+	aiPlayer := ai.NewDefaultAIPlayer()
+	td.Cmp(t, game.WithContext(aiPlayer), aiPlayer)
+}
+
+func TestAIPlayerCancelled(t *testing.T) {
+	g := game.NewGame(game.GameOptions{
+		Border:      3,
+		PlayerCount: 2,
+		Victory:     &game.EightDirStrikeVictoryChecker{VictoryLength: 6},
+	})
+
+	td.CmpNoError(t, g.Play(geom.Offset{X: 0, Y: 0}))
+
+	// The search is deep enough not to end before the deadline
+	p := ai.NewDefaultAIPlayer()
+	p.SearchDepth = 4
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := p.MakeMoveContext(ctx, g.Snapshot())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error [%v], want [%v]", err, context.DeadlineExceeded)
+	}
+
+	// The search is taken back, so the game may go on
+	p.SearchDepth = 1
+	move, err := p.MakeMoveContext(context.Background(), g.Snapshot())
+	td.CmpNoError(t, err)
+	td.CmpNoError(t, g.Play(move))
+}
+
+// followerAgent records the game it follows
+type followerAgent struct {
+	blockingAgent
+
+	started game.View
+	events  []game.GameEvent
+}
+
+func (a *followerAgent) StartGame(g game.View) {
+	a.started = g
+}
+
+func (a *followerAgent) FollowGame(event game.GameEvent) {
+	a.events = append(a.events, event)
+}
+
+func TestStartFollowing(t *testing.T) {
+	g := game.NewGame(game.GameOptions{
+		Border:      2,
+		PlayerCount: 2,
+		Turns:       game.SingleStoneTurns,
+		Victory:     &game.EightDirStrikeVictoryChecker{VictoryLength: 6},
+	})
+
+	td.CmpNoError(t, g.Play(geom.Offset{X: 0, Y: 0}))
+
+	follower := &followerAgent{}
+	stop := game.StartFollowing(g, []game.PlayerAgent{blockingAgent{}, follower})
+
+	td.Cmp(t, follower.started.MoveNumber(), 2)
+
+	td.CmpNoError(t, g.Play(geom.Offset{X: 1, Y: 0}))
+	g.UndoLastMove()
+
+	move := game.PlayerMove{Cell: geom.Offset{X: 1, Y: 0}, Player: game.P2}
+	td.Cmp(t, follower.events, []game.GameEvent{
+		game.MoveMadeEvent{Move: move, MoveNumber: 2},
+		game.MoveUndoneEvent{Move: move, MoveNumber: 2},
+	})
+
+	stop()
+	td.CmpNoError(t, g.Play(geom.Offset{X: 1, Y: 0}))
+	td.Cmp(t, len(follower.events), 2)
+}
+
+func TestAIPlayerFollowsUndo(t *testing.T) {
+	g := game.NewGame(game.GameOptions{
+		BoardSize:   geom.Offset{X: 3, Y: 1},
+		PlayerCount: 2,
+		Turns:       game.SingleStoneTurns,
+		Victory:     &game.EightDirStrikeVictoryChecker{VictoryLength: 3},
+	})
+
+	p := ai.NewDefaultAIPlayer()
+	p.SearchDepth = 1
+	game.StartFollowing(g, []game.PlayerAgent{p})
+
+	// The board is filled up, then the last two stones are taken back
+	for g.MoveNumber() <= 3 {
+		move, err := p.MakeMoveContext(context.Background(), g.Snapshot())
+		td.CmpNoError(t, err)
+		td.CmpNoError(t, g.Play(move))
+	}
+
+	g.UndoLastMove()
+	g.UndoLastMove()
+
+	// The AI sees the cells freed
+	move, err := p.MakeMoveContext(context.Background(), g.Snapshot())
+	td.CmpNoError(t, err)
+	td.CmpNoError(t, g.Play(move))
+}
+
+func TestAIPlayerRespectsLayout(t *testing.T) {
+	// The center, where the AI starts on empty boards, is blocked
+	layout, err := game.ParseLayout(strings.NewReader(".....\n.###.\n.###.\n.###.\n.....\n"))
+	td.CmpNoError(t, err)
+
+	for _, depth := range []int{1, 2} {
+		g := game.NewGame(game.GameOptions{
+			Layout:      layout,
+			PlayerCount: 2,
+			Victory:     &game.EightDirStrikeVictoryChecker{VictoryLength: 4},
+		})
+
+		p := ai.NewDefaultAIPlayer()
+		p.SearchDepth = depth
+
+		for i := 0; i < 6 && !g.Over(); i++ {
+			move, err := p.MakeMoveContext(context.Background(), g.Snapshot())
+			td.CmpNoError(t, err)
+			td.CmpNoError(t, g.ValidateMove(move, g.PlayerToMove()), "depth %d, move %d at %v", depth, i+1, move)
+			td.CmpNoError(t, g.Play(move))
+		}
+	}
+}
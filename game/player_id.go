@@ -7,23 +7,27 @@ type PlayerID int
 const (
 	P1 PlayerID = iota
 	P2
+	P3
+	P4
 )
 
-func (p PlayerID) Other() PlayerID {
-	if p == P1 {
-		return P2
-	} else if p == P2 {
-		return P1
+// NextPlayer returns the player that follows p in a game of playerCount players.
+// Players take turns in the order of their IDs, wrapping around after the last one.
+func (p PlayerID) NextPlayer(playerCount int) PlayerID {
+	if !p.IsValid(playerCount) {
+		panic(fmt.Sprintf("PlayerID: get next player: player is invalid (value=%d, player count=%d)", p, playerCount))
 	}
 
-	panic(fmt.Sprintf("PlayerID: get other player: player is invalid (value=%d)", p))
+	return (p + 1) % PlayerID(playerCount)
+}
+
+func (p PlayerID) IsValid(playerCount int) bool {
+	return p >= 0 && int(p) < playerCount
 }
 
 func (p PlayerID) String() string {
-	if p == P1 {
-		return "P1"
-	} else if p == P2 {
-		return "P2"
+	if p >= 0 {
+		return fmt.Sprintf("P%d", int(p)+1)
 	}
 
 	return fmt.Sprintf("UnknownP%d", int(p))
@@ -3,6 +3,6 @@ package game
 import "github.com/kitsunemikan/six-purrpurrs/geom"
 
 type PlayerMove struct {
-	Cell   geom.Offset
-	Player PlayerID
+	Cell   geom.Offset `json:"cell"`
+	Player PlayerID    `json:"player"`
 }
@@ -0,0 +1,212 @@
+package game
+
+import (
+	"github.com/kitsunemikan/six-purrpurrs/geom"
+)
+
+// RenjuRules forbid the Restricted player (P1 in Renju) to make double-threes,
+// double-fours and overlines. A move that makes a strike of exactly the victory
+// length is never forbidden.
+//
+// A three is a line of stones that can be turned into a straight four with one more
+// stone, and a four is a line of stones that can be turned into a strike of exactly
+// the victory length with one more stone. Both may have gaps. Unlike tournament
+// Renju, whether the move completing a three into a straight four is itself
+// forbidden is not taken into account.
+type RenjuRules struct {
+	Restricted PlayerID
+}
+
+type lineCell int
+
+const (
+	lineEmpty lineCell = iota
+	lineOwn
+	lineBlocked
+)
+
+// renjuLine is a line of cells around a prospective move located at the center
+type renjuLine struct {
+	cells  []lineCell
+	center int
+
+	victoryLength int
+}
+
+func newRenjuLine(strikes *StrikeSet, victoryLength int, pos geom.Offset, player PlayerID, dir StrikeDir) renjuLine {
+	// Strikes longer than the victory length + 1 matter only as far as
+	// they are overlines, so there's no need to look further
+	radius := victoryLength + 1
+
+	line := renjuLine{
+		cells:         make([]lineCell, 2*radius+1),
+		center:        radius,
+		victoryLength: victoryLength,
+	}
+
+	for i := range line.cells {
+		cell := pos.Add(dir.Offset().ScaleUp(i - radius))
+
+		owner, occupied := strikes.PlayerAt(cell)
+		switch {
+		case i == radius, occupied && owner == player:
+			line.cells[i] = lineOwn
+		case occupied, strikes.IsBlocked(cell):
+			line.cells[i] = lineBlocked
+		}
+	}
+
+	return line
+}
+
+func (l renjuLine) at(i int) lineCell {
+	if i < 0 || i >= len(l.cells) {
+		return lineBlocked
+	}
+
+	return l.cells[i]
+}
+
+// with returns a copy of the line with an own stone at i
+func (l renjuLine) with(i int) renjuLine {
+	cells := make([]lineCell, len(l.cells))
+	copy(cells, l.cells)
+	cells[i] = lineOwn
+
+	l.cells = cells
+	return l
+}
+
+// runLen returns the length of the contiguous own stones through i
+func (l renjuLine) runLen(i int) int {
+	start, end := i, i
+	for l.at(start-1) == lineOwn {
+		start--
+	}
+
+	for l.at(end+1) == lineOwn {
+		end++
+	}
+
+	return end - start + 1
+}
+
+// fivePoints returns empty cells that complete a strike of exactly the victory
+// length going through the center
+func (l renjuLine) fivePoints() []int {
+	var points []int
+
+	for i := l.center - l.victoryLength + 1; i < l.center+l.victoryLength; i++ {
+		if l.at(i) != lineEmpty {
+			continue
+		}
+
+		withStone := l.with(i)
+		if withStone.runLen(i) != l.victoryLength {
+			continue
+		}
+
+		// The strike must go through the center
+		start, end := i, l.center
+		if start > end {
+			start, end = end, start
+		}
+
+		if withStone.spans(start, end) {
+			points = append(points, i)
+		}
+	}
+
+	return points
+}
+
+// spans checks that all cells between start and end are own stones
+func (l renjuLine) spans(start, end int) bool {
+	for i := start; i <= end; i++ {
+		if l.at(i) != lineOwn {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (l renjuLine) fourCount() int {
+	points := l.fivePoints()
+	if len(points) == 0 {
+		return 0
+	}
+
+	// A contiguous four may be completed from both sides, but it's still one four
+	if l.runLen(l.center) == l.victoryLength-1 {
+		return 1
+	}
+
+	return len(points)
+}
+
+func (l renjuLine) isStraightFour() bool {
+	return l.runLen(l.center) == l.victoryLength-1 && len(l.fivePoints()) == 2
+}
+
+func (l renjuLine) hasThree() bool {
+	for i := l.center - l.victoryLength + 1; i < l.center+l.victoryLength; i++ {
+		if l.at(i) != lineEmpty {
+			continue
+		}
+
+		if l.with(i).isStraightFour() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r RenjuRules) CheckMove(strikes *StrikeSet, victoryLength int, pos geom.Offset, player PlayerID) error {
+	if player != r.Restricted {
+		return nil
+	}
+
+	lines := make([]renjuLine, 0, len(strikes.Dirs()))
+	for _, dir := range strikes.Dirs() {
+		line := newRenjuLine(strikes, victoryLength, pos, player, dir)
+
+		// A five takes precedence over any forbidden pattern
+		if line.runLen(line.center) == victoryLength {
+			return nil
+		}
+
+		lines = append(lines, line)
+	}
+
+	forbidden := func(reason ForbiddenReason) error {
+		return &ForbiddenMoveError{Cell: pos, Player: player, Reason: reason}
+	}
+
+	for _, line := range lines {
+		if line.runLen(line.center) > victoryLength {
+			return forbidden(ForbiddenOverline)
+		}
+	}
+
+	fours, threes := 0, 0
+	for _, line := range lines {
+		lineFours := line.fourCount()
+		fours += lineFours
+
+		if lineFours == 0 && line.hasThree() {
+			threes++
+		}
+	}
+
+	if fours >= 2 {
+		return forbidden(ForbiddenDoubleFour)
+	}
+
+	if threes >= 2 {
+		return forbidden(ForbiddenDoubleThree)
+	}
+
+	return nil
+}
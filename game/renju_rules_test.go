@@ -0,0 +1,216 @@
+package game_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kitsunemikan/six-purrpurrs/game"
+	"github.com/kitsunemikan/six-purrpurrs/geom"
+)
+
+// GameFromRows creates a game with stones placed as described by rows,
+// where X is P1 and O is P2. The position of the '*' character is returned
+// as a cell of interest
+func GameFromRows(opt game.GameOptions, rows []string) (*game.GameState, geom.Offset) {
+	g := game.NewGame(opt)
+
+	var marked geom.Offset
+	for y, row := range rows {
+		for x, ch := range row {
+			cell := geom.Offset{X: x, Y: y}
+
+			switch ch {
+			case 'X':
+				g.MarkCell(cell, game.P1)
+			case 'O':
+				g.MarkCell(cell, game.P2)
+			case '*':
+				marked = cell
+			}
+		}
+	}
+
+	return g, marked
+}
+
+func TestRenjuRules(t *testing.T) {
+	cases := []struct {
+		desc       string
+		player     game.PlayerID
+		rows       []string
+		wantReason game.ForbiddenReason
+		forbidden  bool
+	}{
+		{
+			"open three is allowed",
+			game.P1,
+			[]string{
+				".......",
+				".XX*...",
+				".......",
+			},
+			0, false,
+		},
+		{
+			"double three",
+			game.P1,
+			[]string{
+				".......",
+				"...X...",
+				"...X...",
+				".XX*...",
+				".......",
+				".......",
+			},
+			game.ForbiddenDoubleThree, true,
+		},
+		{
+			"double three with a gapped three",
+			game.P1,
+			[]string{
+				"........",
+				"....X...",
+				"....X...",
+				"........",
+				".XX.*...",
+				"........",
+				"........",
+			},
+			game.ForbiddenDoubleThree, true,
+		},
+		{
+			"blocked three doesn't count",
+			game.P1,
+			[]string{
+				".......",
+				"...X...",
+				"...X...",
+				"OXX*...",
+				".......",
+				".......",
+			},
+			0, false,
+		},
+		{
+			"double three is allowed for P2",
+			game.P2,
+			[]string{
+				".......",
+				"...O...",
+				"...O...",
+				".OO*...",
+				".......",
+				".......",
+			},
+			0, false,
+		},
+		{
+			"double four",
+			game.P1,
+			[]string{
+				"........",
+				"....X...",
+				"....X...",
+				"....X...",
+				"OXXX*...",
+				"........",
+				"........",
+			},
+			game.ForbiddenDoubleFour, true,
+		},
+		{
+			"double four in a single line",
+			game.P1,
+			[]string{
+				"...........",
+				".X.XX*.X...",
+				"...........",
+			},
+			game.ForbiddenDoubleFour, true,
+		},
+		{
+			"four-three is allowed",
+			game.P1,
+			[]string{
+				"........",
+				"....X...",
+				"....X...",
+				"....X...",
+				"..XX*...",
+				"........",
+				"........",
+			},
+			0, false,
+		},
+		{
+			"overline",
+			game.P1,
+			[]string{
+				"..........",
+				".XXX*XX...",
+				"..........",
+			},
+			game.ForbiddenOverline, true,
+		},
+		{
+			"five takes precedence over a double three",
+			game.P1,
+			[]string{
+				"..........",
+				"....X.....",
+				"....X.....",
+				".XXX*X....",
+				"..........",
+			},
+			0, false,
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.desc, func(t *testing.T) {
+			g, pos := GameFromRows(game.GameOptions{
+				Border:      2,
+				PlayerCount: 2,
+				Rules:       game.RenjuRules{Restricted: game.P1},
+				Victory:     &game.EightDirStrikeVictoryChecker{VictoryLength: 5},
+			}, test.rows)
+
+			err := g.CheckMove(pos, test.player)
+			if !test.forbidden {
+				if err != nil {
+					t.Errorf("got error [%v], want none", err)
+				}
+				return
+			}
+
+			var forbiddenErr *game.ForbiddenMoveError
+			if !errors.As(err, &forbiddenErr) {
+				t.Fatalf("got error [%v], want a forbidden move error", err)
+			}
+
+			if forbiddenErr.Reason != test.wantReason {
+				t.Errorf("got reason %v, want %v", forbiddenErr.Reason, test.wantReason)
+			}
+		})
+	}
+}
+
+func TestRenjuRulesBoardEdge(t *testing.T) {
+	g := game.NewGame(game.GameOptions{
+		BoardSize:   geom.Offset{X: 15, Y: 15},
+		PlayerCount: 2,
+		Rules:       game.RenjuRules{Restricted: game.P1},
+		Victory:     &game.ExactStrikeVictoryChecker{VictoryLength: 5},
+	})
+
+	// The row three runs into the left edge, so only the column three is open
+	for _, cell := range []geom.Offset{{X: -7, Y: 0}, {X: -6, Y: 0}, {X: -5, Y: 2}, {X: -5, Y: 3}} {
+		if err := g.MarkCell(cell, game.P1); err != nil {
+			t.Fatalf("mark %v: %v", cell, err)
+		}
+	}
+
+	if err := g.CheckMove(geom.Offset{X: -5, Y: 0}, game.P1); err != nil {
+		t.Errorf("got error [%v], want none", err)
+	}
+}
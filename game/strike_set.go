@@ -1,7 +1,6 @@
 package game
 
 import (
-	"errors"
 	"fmt"
 	"strings"
 
@@ -22,6 +21,16 @@ var (
 
 var StrikeDirs = []StrikeDir{StrikeRightUp, StrikeRight, StrikeRightDown, StrikeDown}
 
+// In axial hex coordinates the down axis goes to the lower-right neighbour,
+// so together with the right and the right-up axes it covers all six neighbours
+var (
+	HexStrikeRightUp = StrikeDir{X: 1, Y: -1, FixedID: 0}
+	HexStrikeRight   = StrikeDir{X: 1, Y: 0, FixedID: 1}
+	HexStrikeDown    = StrikeDir{X: 0, Y: 1, FixedID: 2}
+)
+
+var HexStrikeDirs = []StrikeDir{HexStrikeRightUp, HexStrikeRight, HexStrikeDown}
+
 type Strike struct {
 	Player           PlayerID
 	Start            geom.Offset
@@ -29,50 +38,203 @@ type Strike struct {
 	Len              int
 	ExtendableBefore bool
 	ExtendableAfter  bool
+
+	// Wrap is the bound of a wrapping board the strike is on, empty otherwise
+	Wrap geom.Rect
+}
+
+// cellAt returns the i-th cell of the strike counting from the start
+func (s *Strike) cellAt(i int) geom.Offset {
+	cell := s.Start.Add(s.Dir.Offset().ScaleUp(i))
+	if s.Wrap.W == 0 {
+		return cell
+	}
+
+	return cell.WrapIntoRect(s.Wrap)
+}
+
+// indexOf returns the index of the strike cell counting from the start
+func (s *Strike) indexOf(cell geom.Offset) int {
+	for i := 0; i < s.Len; i++ {
+		if s.cellAt(i) == cell {
+			return i
+		}
+	}
+
+	panic(fmt.Sprintf("strike: index of %v: the cell isn't a part of the strike %v", cell, s.AsCells()))
+}
+
+// IsRing reports whether the strike has come full circle on a wrapping board
+func (s *Strike) IsRing() bool {
+	return s.Wrap.W != 0 && s.Len > 1 && s.cellAt(s.Len) == s.Start
 }
 
 func (s *Strike) AsCells() []geom.Offset {
 	cells := make([]geom.Offset, s.Len)
 
-	for i, cell := 0, s.Start; i < s.Len; i, cell = i+1, cell.Add(s.Dir.Offset()) {
-		cells[i] = cell
+	for i := range cells {
+		cells[i] = s.cellAt(i)
 	}
 
 	return cells
 }
 
 type StrikeSet struct {
+	dirs []StrikeDir
+
+	// wrap is the bound of a wrapping board, if non-empty
+	wrap geom.Rect
+
+	// bound is the bound of a bounded board that doesn't wrap, if non-empty
+	bound geom.Rect
+
 	strikes        []Strike
 	deletedStrikes []int
 
 	board   map[geom.Offset][]int
 	players map[geom.Offset]PlayerID
+
+	// blocked cells break strikes like opponent moves, but never change
+	blocked map[geom.Offset]struct{}
 }
 
 func NewStrikeSet() *StrikeSet {
+	return NewStrikeSetWithDirs(StrikeDirs)
+}
+
+// NewStrikeSetWithDirs tracks strikes along the given axes. There may be at most
+// 4 axes, and their FixedIDs must be their indices
+func NewStrikeSetWithDirs(dirs []StrikeDir) *StrikeSet {
+	if len(dirs) > 4 {
+		panic(fmt.Sprintf("new strike set: at most 4 strike directions are supported, got %d", len(dirs)))
+	}
+
+	for i, dir := range dirs {
+		if dir.FixedID != i {
+			panic(fmt.Sprintf("new strike set: strike direction %v has fixed ID %d at index %d", dir, dir.FixedID, i))
+		}
+	}
+
 	return &StrikeSet{
+		dirs:    dirs,
 		strikes: nil,
 		board:   make(map[geom.Offset][]int),
 		players: make(map[geom.Offset]PlayerID),
+		blocked: make(map[geom.Offset]struct{}),
+	}
+}
+
+// NewWrappingStrikeSet tracks strikes on a board whose opposite edges are glued
+// together, so strikes continue past the right edge onto the left one and so on
+func NewWrappingStrikeSet(dirs []StrikeDir, bound geom.Rect) *StrikeSet {
+	if bound.W <= 0 || bound.H <= 0 {
+		panic(fmt.Sprintf("new wrapping strike set: bound must be non-empty (bound=%v)", bound))
 	}
+
+	s := NewStrikeSetWithDirs(dirs)
+	s.wrap = bound
+
+	return s
+}
+
+// NewBoundedStrikeSet tracks strikes on a board that ends at the bound, so
+// the cells outside of it are reported as blocked
+func NewBoundedStrikeSet(dirs []StrikeDir, bound geom.Rect) *StrikeSet {
+	if bound.W <= 0 || bound.H <= 0 {
+		panic(fmt.Sprintf("new bounded strike set: bound must be non-empty (bound=%v)", bound))
+	}
+
+	s := NewStrikeSetWithDirs(dirs)
+	s.bound = bound
+
+	return s
+}
+
+// Clone returns a deep copy of the strike set
+func (s *StrikeSet) Clone() *StrikeSet {
+	clone := &StrikeSet{
+		dirs:  s.dirs,
+		wrap:  s.wrap,
+		bound: s.bound,
+
+		strikes:        make([]Strike, len(s.strikes)),
+		deletedStrikes: make([]int, len(s.deletedStrikes)),
+
+		board:   make(map[geom.Offset][]int, len(s.board)),
+		players: make(map[geom.Offset]PlayerID, len(s.players)),
+		blocked: make(map[geom.Offset]struct{}, len(s.blocked)),
+	}
+
+	copy(clone.strikes, s.strikes)
+	copy(clone.deletedStrikes, s.deletedStrikes)
+
+	for cell, strikeIDs := range s.board {
+		clone.board[cell] = append([]int(nil), strikeIDs...)
+	}
+
+	for cell, player := range s.players {
+		clone.players[cell] = player
+	}
+
+	for cell := range s.blocked {
+		clone.blocked[cell] = struct{}{}
+	}
+
+	return clone
+}
+
+// normalize maps cells of a wrapping board into its bound
+func (s *StrikeSet) normalize(cell geom.Offset) geom.Offset {
+	if s.wrap.W == 0 {
+		return cell
+	}
+
+	return cell.WrapIntoRect(s.wrap)
+}
+
+// MarkBlocked makes the cell permanently unavailable for strikes.
+// Blocked cells should be marked before any moves are made
+func (s *StrikeSet) MarkBlocked(cell geom.Offset) error {
+	cell = s.normalize(cell)
+
+	if _, occupied := s.players[cell]; occupied {
+		return fmt.Errorf("strike set: mark blocked at %v: %w", cell, ErrCellOccupied)
+	}
+
+	s.blocked[cell] = struct{}{}
+	return nil
+}
+
+// IsBlocked reports whether the cell is an obstacle. Cells past the edge
+// of a bounded board are obstacles too
+func (s *StrikeSet) IsBlocked(cell geom.Offset) bool {
+	if s.bound.W != 0 && !cell.IsInsideRect(s.bound) {
+		return true
+	}
+
+	_, blocked := s.blocked[s.normalize(cell)]
+	return blocked
 }
 
 // It is assumed that the board is filled only with unoccupied cells, and invalid cells don't exist
 // TODO: add error handling
 func (s *StrikeSet) MakeMove(atCell geom.Offset, as PlayerID) error {
-	move := PlayerMove{Cell: atCell, Player: as}
+	move := PlayerMove{Cell: s.normalize(atCell), Player: as}
 
 	if _, exists := s.players[move.Cell]; exists {
-		// TODO: add test for the error + make sentinel + wrap error
-		return errors.New("strike set: make move: move already done")
+		return fmt.Errorf("strike set: make move at %v: %w", move.Cell, ErrCellOccupied)
+	}
+
+	if _, blocked := s.blocked[move.Cell]; blocked {
+		return fmt.Errorf("strike set: make move at %v: cell is blocked: %w", move.Cell, ErrCellUnavailable)
 	}
 
 	s.players[move.Cell] = move.Player
 
-	for _, dir := range StrikeDirs {
+	for _, dir := range s.dirs {
 		// Create reference arary, if it's a new cell
 		if _, ok := s.board[move.Cell]; !ok {
-			strikeRef := make([]int, len(StrikeDirs))
+			strikeRef := make([]int, len(s.dirs))
 			for i := range strikeRef {
 				strikeRef[i] = -1
 			}
@@ -82,22 +244,22 @@ func (s *StrikeSet) MakeMove(atCell geom.Offset, as PlayerID) error {
 
 		enemyBeforeStrikeID := -1
 		beforeStrikeID := -1
-		beforeCell := move.Cell.Sub(dir.Offset())
+		beforeCell := s.normalize(move.Cell.Sub(dir.Offset()))
 		if p, ok := s.players[beforeCell]; ok {
 			if p == move.Player {
 				beforeStrikeID = s.board[beforeCell][dir.FixedID]
-			} else if p == move.Player.Other() {
+			} else {
 				enemyBeforeStrikeID = s.board[beforeCell][dir.FixedID]
 			}
 		}
 
 		enemyAfterStrikeID := -1
 		afterStrikeID := -1
-		afterCell := move.Cell.Add(dir.Offset())
+		afterCell := s.normalize(move.Cell.Add(dir.Offset()))
 		if p, ok := s.players[afterCell]; ok {
 			if p == move.Player {
 				afterStrikeID = s.board[afterCell][dir.FixedID]
-			} else if p == move.Player.Other() {
+			} else {
 				enemyAfterStrikeID = s.board[afterCell][dir.FixedID]
 			}
 		}
@@ -119,6 +281,15 @@ func (s *StrikeSet) MakeMove(atCell geom.Offset, as PlayerID) error {
 			s.strikes[afterStrikeID].Start = move.Cell
 			s.strikes[afterStrikeID].Len++
 
+		case beforeStrikeID != -1 && beforeStrikeID == afterStrikeID:
+			// On a wrapping board the strike has come full circle,
+			// so there's nowhere left to extend it
+
+			s.board[move.Cell][dir.FixedID] = beforeStrikeID
+			s.strikes[beforeStrikeID].Len++
+			s.strikes[beforeStrikeID].ExtendableBefore = false
+			s.strikes[beforeStrikeID].ExtendableAfter = false
+
 		case beforeStrikeID != -1 && afterStrikeID != -1:
 			s.strikes[beforeStrikeID].Len += s.strikes[afterStrikeID].Len + 1
 			s.strikes[beforeStrikeID].ExtendableAfter = s.strikes[afterStrikeID].ExtendableAfter
@@ -129,8 +300,7 @@ func (s *StrikeSet) MakeMove(atCell geom.Offset, as PlayerID) error {
 			// Note that there will be no references to the after strike after this
 			// in the board map
 			afterStrike := s.strikes[afterStrikeID]
-			for cell, i := afterStrike.Start, 0; i < afterStrike.Len; i, cell = i+1, cell.Add(dir.Offset()) {
-				// TODO: buggg!!! cell is not updated!
+			for _, cell := range afterStrike.AsCells() {
 				s.board[cell][dir.FixedID] = beforeStrikeID
 			}
 
@@ -158,12 +328,22 @@ func (s *StrikeSet) MakeMove(atCell geom.Offset, as PlayerID) error {
 				Dir:              dir,
 				ExtendableBefore: true,
 				ExtendableAfter:  true,
+
+				Wrap: s.wrap,
 			}
 
 			s.board[move.Cell][dir.FixedID] = newStrikeID
 		}
 
 		assignedStrikeID := s.board[move.Cell][dir.FixedID]
+		if _, blocked := s.blocked[beforeCell]; blocked {
+			s.strikes[assignedStrikeID].ExtendableBefore = false
+		}
+
+		if _, blocked := s.blocked[afterCell]; blocked {
+			s.strikes[assignedStrikeID].ExtendableAfter = false
+		}
+
 		if enemyBeforeStrikeID != -1 {
 			s.strikes[assignedStrikeID].ExtendableBefore = false
 
@@ -181,46 +361,49 @@ func (s *StrikeSet) MakeMove(atCell geom.Offset, as PlayerID) error {
 }
 
 func (s *StrikeSet) MarkUnoccupied(cell geom.Offset) error {
+	cell = s.normalize(cell)
+
 	if _, occupied := s.players[cell]; !occupied {
-		// TODO: proper error
-		return errors.New("strike set: mark unoccupied: cell is already unoccupied")
+		return fmt.Errorf("strike set: mark unoccupied at %v: %w", cell, ErrCellUnoccupied)
 	}
 
-	for _, dir := range StrikeDirs {
+	for _, dir := range s.dirs {
 		strikeID := s.board[cell][dir.FixedID]
 		s.board[cell][dir.FixedID] = -1
 
-		// Derestrict oponent strikes if any
-		if afterPlayer, moveExists := s.players[cell.Add(dir.Offset())]; moveExists {
-			if afterPlayer.Other() == s.players[cell] {
-				afterCell := cell.Add(dir.Offset())
+		// Derestrict oponent strikes if any. Every other player is an opponent
+		afterCell := s.normalize(cell.Add(dir.Offset()))
+		if afterPlayer, moveExists := s.players[afterCell]; moveExists {
+			if afterPlayer != s.players[cell] {
 				enemyAfterStrikeID := s.board[afterCell][dir.FixedID]
 
 				s.strikes[enemyAfterStrikeID].ExtendableBefore = true
 			}
 		}
 
-		if beforePlayer, moveExists := s.players[cell.Sub(dir.Offset())]; moveExists {
-			if beforePlayer.Other() == s.players[cell] {
-				beforeCell := cell.Sub(dir.Offset())
+		beforeCell := s.normalize(cell.Sub(dir.Offset()))
+		if beforePlayer, moveExists := s.players[beforeCell]; moveExists {
+			if beforePlayer != s.players[cell] {
 				enemyBeforeStrikeID := s.board[beforeCell][dir.FixedID]
 
 				s.strikes[enemyBeforeStrikeID].ExtendableAfter = true
 			}
 		}
 
+		// A ring has no sides, so removing any cell turns it into
+		// a strike starting right after the removed cell
+		if s.strikes[strikeID].IsRing() {
+			s.strikes[strikeID].Start = afterCell
+			s.strikes[strikeID].Len--
+			s.strikes[strikeID].ExtendableBefore = true
+			s.strikes[strikeID].ExtendableAfter = true
+			continue
+		}
+
 		// Determine the index of the cell to be removed inside the strike
 		// We will handle different cases depending whether it's located on the sides
 		// or somewhere in the middle
-		ds := cell.Sub(s.strikes[strikeID].Start)
-		shift := 0
-		if shift < ds.X {
-			shift = ds.X
-		}
-
-		if shift < ds.Y {
-			shift = ds.Y
-		}
+		shift := s.strikes[strikeID].indexOf(cell)
 
 		atStart := shift == 0
 		atEnd := shift == s.strikes[strikeID].Len-1
@@ -232,7 +415,7 @@ func (s *StrikeSet) MarkUnoccupied(cell geom.Offset) error {
 			s.deletedStrikes = append(s.deletedStrikes, strikeID)
 
 		case atStart && !atEnd:
-			s.strikes[strikeID].Start = cell.Add(dir.Offset())
+			s.strikes[strikeID].Start = afterCell
 			s.strikes[strikeID].Len--
 			s.strikes[strikeID].ExtendableBefore = true
 
@@ -254,16 +437,18 @@ func (s *StrikeSet) MarkUnoccupied(cell geom.Offset) error {
 			}
 
 			// Route second half of the strike to the newly created strike
-			for i := 1; i < s.strikes[strikeID].Len-shift; i++ {
-				rerouteCell := cell.Add(dir.Offset().ScaleUp(i))
+			for i := shift + 1; i < s.strikes[strikeID].Len; i++ {
+				rerouteCell := s.strikes[strikeID].cellAt(i)
 				s.board[rerouteCell][dir.FixedID] = newStrikeID
 			}
 
-			s.strikes[newStrikeID].Start = cell.Add(dir.Offset())
+			s.strikes[newStrikeID].Player = s.strikes[strikeID].Player
+			s.strikes[newStrikeID].Start = afterCell
 			s.strikes[newStrikeID].Len = s.strikes[strikeID].Len - shift - 1
 			s.strikes[newStrikeID].Dir = dir
 			s.strikes[newStrikeID].ExtendableBefore = true
 			s.strikes[newStrikeID].ExtendableAfter = s.strikes[strikeID].ExtendableAfter
+			s.strikes[newStrikeID].Wrap = s.wrap
 
 			s.strikes[strikeID].Len = shift
 			s.strikes[strikeID].ExtendableAfter = true
@@ -276,10 +461,23 @@ func (s *StrikeSet) MarkUnoccupied(cell geom.Offset) error {
 	return nil
 }
 
+// Dirs returns the axes along which strikes are tracked
+func (s *StrikeSet) Dirs() []StrikeDir {
+	return s.dirs
+}
+
+// PlayerAt returns the player that has made a move at the cell, if any
+func (s *StrikeSet) PlayerAt(cell geom.Offset) (PlayerID, bool) {
+	player, occupied := s.players[s.normalize(cell)]
+	return player, occupied
+}
+
+// StrikesThrough returns strikes indexed by the FixedID of their directions.
+// Unused directions have zero-length strikes
 func (s *StrikeSet) StrikesThrough(cell geom.Offset) [4]Strike {
 	var strikes [4]Strike
 
-	strikeRefs := s.board[cell]
+	strikeRefs := s.board[s.normalize(cell)]
 	for i, strikeID := range strikeRefs {
 		if strikeID == -1 {
 			// strikes[i].Len will be 0
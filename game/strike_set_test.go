@@ -1,6 +1,7 @@
 package game_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/kitsunemikan/six-purrpurrs/game"
@@ -24,12 +25,15 @@ func StrikeFromStr(start geom.Offset, dir game.StrikeDir, desc string) (strike g
 		i = 1
 	}
 
-	if desc[i] == 'X' {
+	switch desc[i] {
+	case 'X':
 		strike.Player = game.P1
-	} else if desc[i] == 'O' {
+	case 'O':
 		strike.Player = game.P2
-	} else {
-		panic("strike from str: unknown player avatar, should be either X or O")
+	case 'A':
+		strike.Player = game.P3
+	default:
+		panic("strike from str: unknown player avatar, should be either X, O or A")
 	}
 
 	for i < len(desc) && desc[i] != '.' {
@@ -400,6 +404,33 @@ func TestStrikeSetMakeMove(t *testing.T) {
 				StrikeFromStr(geom.Offset{X: 2, Y: 0}, game.StrikeRight, "O."),
 			},
 		},
+		{
+			"Third player blocks strikes of both other players",
+			[]game.PlayerMove{
+				{Cell: geom.Offset{X: 0, Y: 0}, Player: game.P1},
+				{Cell: geom.Offset{X: 2, Y: 0}, Player: game.P2},
+				{Cell: geom.Offset{X: 1, Y: 0}, Player: game.P3},
+			},
+			[]game.Strike{
+				// P1
+				StrikeFromStr(geom.Offset{X: 0, Y: 0}, game.StrikeRightUp, ".X."),
+				StrikeFromStr(geom.Offset{X: 0, Y: 0}, game.StrikeRight, ".X"),
+				StrikeFromStr(geom.Offset{X: 0, Y: 0}, game.StrikeRightDown, ".X."),
+				StrikeFromStr(geom.Offset{X: 0, Y: 0}, game.StrikeDown, ".X."),
+
+				// P2
+				StrikeFromStr(geom.Offset{X: 2, Y: 0}, game.StrikeRightUp, ".O."),
+				StrikeFromStr(geom.Offset{X: 2, Y: 0}, game.StrikeRight, "O."),
+				StrikeFromStr(geom.Offset{X: 2, Y: 0}, game.StrikeRightDown, ".O."),
+				StrikeFromStr(geom.Offset{X: 2, Y: 0}, game.StrikeDown, ".O."),
+
+				// P3
+				StrikeFromStr(geom.Offset{X: 1, Y: 0}, game.StrikeRightUp, ".A."),
+				StrikeFromStr(geom.Offset{X: 1, Y: 0}, game.StrikeRight, "A"),
+				StrikeFromStr(geom.Offset{X: 1, Y: 0}, game.StrikeRightDown, ".A."),
+				StrikeFromStr(geom.Offset{X: 1, Y: 0}, game.StrikeDown, ".A."),
+			},
+		},
 		{
 			"1-len strike after a merged strike",
 			[]game.PlayerMove{
@@ -593,6 +624,26 @@ func TestStrikeSetMarkUnoccupied(t *testing.T) {
 				StrikeFromStr(geom.Offset{X: 0, Y: 0}, game.StrikeDown, ".X."),
 			},
 		},
+		{
+			"derestrict strikes of two different opponents",
+			[]game.PlayerMove{
+				{Cell: geom.Offset{X: 0, Y: 0}, Player: game.P1},
+				{Cell: geom.Offset{X: 2, Y: 0}, Player: game.P2},
+				{Cell: geom.Offset{X: 1, Y: 0}, Player: game.P3},
+			},
+			geom.Offset{X: 1, Y: 0},
+			[]game.Strike{
+				StrikeFromStr(geom.Offset{X: 0, Y: 0}, game.StrikeRightUp, ".X."),
+				StrikeFromStr(geom.Offset{X: 0, Y: 0}, game.StrikeRight, ".X."),
+				StrikeFromStr(geom.Offset{X: 0, Y: 0}, game.StrikeRightDown, ".X."),
+				StrikeFromStr(geom.Offset{X: 0, Y: 0}, game.StrikeDown, ".X."),
+
+				StrikeFromStr(geom.Offset{X: 2, Y: 0}, game.StrikeRightUp, ".O."),
+				StrikeFromStr(geom.Offset{X: 2, Y: 0}, game.StrikeRight, ".O."),
+				StrikeFromStr(geom.Offset{X: 2, Y: 0}, game.StrikeRightDown, ".O."),
+				StrikeFromStr(geom.Offset{X: 2, Y: 0}, game.StrikeDown, ".O."),
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -645,3 +696,257 @@ func TestStrikeSetStrikesThrough(t *testing.T) {
 
 	td.Cmp(t, gotStrike, wantStrike)
 }
+
+func TestHexStrikeSet(t *testing.T) {
+	set := game.NewStrikeSetWithDirs(game.HexTopology.StrikeDirs())
+
+	// Square diagonals aren't strikes on a hex board
+	set.MakeMove(geom.Offset{X: 0, Y: 0}, game.P1)
+	set.MakeMove(geom.Offset{X: 1, Y: 1}, game.P1)
+
+	for _, strike := range set.StrikesThrough(geom.Offset{X: 1, Y: 1}) {
+		td.Cmp(t, strike.Len, td.Lte(1))
+	}
+
+	set.MakeMove(geom.Offset{X: 0, Y: 1}, game.P1)
+	set.MakeMove(geom.Offset{X: 0, Y: 2}, game.P2)
+
+	strikes := set.StrikesThrough(geom.Offset{X: 0, Y: 0})
+	td.Cmp(t, strikes[game.HexStrikeDown.FixedID], game.Strike{
+		Player:           game.P1,
+		Dir:              game.HexStrikeDown,
+		Start:            geom.Offset{X: 0, Y: 0},
+		Len:              2,
+		ExtendableBefore: true,
+		ExtendableAfter:  false,
+	})
+
+	// Only three axes are tracked
+	td.Cmp(t, strikes[3].Len, 0)
+
+	td.Cmp(t, set.MarkUnoccupied(geom.Offset{X: 0, Y: 1}), nil)
+	td.Cmp(t, set.StrikesThrough(geom.Offset{X: 0, Y: 2})[game.HexStrikeDown.FixedID].ExtendableBefore, true)
+}
+
+func WrappedStrikeFromStr(bound geom.Rect, start geom.Offset, dir game.StrikeDir, desc string) game.Strike {
+	strike := StrikeFromStr(start, dir, desc)
+	strike.Wrap = bound
+	return strike
+}
+
+func strikesAlong(strikes []game.Strike, dir game.StrikeDir) []game.Strike {
+	var along []game.Strike
+	for _, strike := range strikes {
+		if strike.Dir.IsEqual(dir) {
+			along = append(along, strike)
+		}
+	}
+
+	return along
+}
+
+func TestStrikeSetWrap(t *testing.T) {
+	bound := geom.Rect{X: 0, Y: 0, W: 4, H: 3}
+
+	tests := []struct {
+		description string
+		moves       []game.PlayerMove
+		toRevert    []geom.Offset
+		dir         game.StrikeDir
+		want        []game.Strike
+	}{
+		{
+			"strike continues past the right edge",
+			[]game.PlayerMove{
+				{Cell: geom.Offset{X: 3, Y: 0}, Player: game.P1},
+				{Cell: geom.Offset{X: 0, Y: 0}, Player: game.P1},
+			},
+			nil,
+			game.StrikeRight,
+			[]game.Strike{
+				WrappedStrikeFromStr(bound, geom.Offset{X: 3, Y: 0}, game.StrikeRight, ".XX."),
+			},
+		},
+		{
+			"strikes merge across the seam",
+			[]game.PlayerMove{
+				{Cell: geom.Offset{X: 2, Y: 1}, Player: game.P1},
+				{Cell: geom.Offset{X: 0, Y: 1}, Player: game.P1},
+				{Cell: geom.Offset{X: 3, Y: 1}, Player: game.P1},
+			},
+			nil,
+			game.StrikeRight,
+			[]game.Strike{
+				WrappedStrikeFromStr(bound, geom.Offset{X: 2, Y: 1}, game.StrikeRight, ".XXX."),
+			},
+		},
+		{
+			"strike continues past the bottom edge",
+			[]game.PlayerMove{
+				{Cell: geom.Offset{X: 1, Y: 2}, Player: game.P2},
+				{Cell: geom.Offset{X: 1, Y: 0}, Player: game.P2},
+			},
+			nil,
+			game.StrikeDown,
+			[]game.Strike{
+				WrappedStrikeFromStr(bound, geom.Offset{X: 1, Y: 2}, game.StrikeDown, ".OO."),
+			},
+		},
+		{
+			"diagonal strike continues past the corner",
+			[]game.PlayerMove{
+				{Cell: geom.Offset{X: 3, Y: 2}, Player: game.P1},
+				{Cell: geom.Offset{X: 0, Y: 0}, Player: game.P1},
+			},
+			nil,
+			game.StrikeRightDown,
+			[]game.Strike{
+				WrappedStrikeFromStr(bound, geom.Offset{X: 3, Y: 2}, game.StrikeRightDown, ".XX."),
+			},
+		},
+		{
+			"opponent blocks across the seam",
+			[]game.PlayerMove{
+				{Cell: geom.Offset{X: 3, Y: 0}, Player: game.P2},
+				{Cell: geom.Offset{X: 0, Y: 0}, Player: game.P1},
+			},
+			nil,
+			game.StrikeRight,
+			[]game.Strike{
+				WrappedStrikeFromStr(bound, geom.Offset{X: 3, Y: 0}, game.StrikeRight, ".O"),
+				WrappedStrikeFromStr(bound, geom.Offset{X: 0, Y: 0}, game.StrikeRight, "X."),
+			},
+		},
+		{
+			"full row becomes a ring that can't be extended",
+			[]game.PlayerMove{
+				{Cell: geom.Offset{X: 0, Y: 0}, Player: game.P1},
+				{Cell: geom.Offset{X: 1, Y: 0}, Player: game.P1},
+				{Cell: geom.Offset{X: 2, Y: 0}, Player: game.P1},
+				{Cell: geom.Offset{X: 3, Y: 0}, Player: game.P1},
+			},
+			nil,
+			game.StrikeRight,
+			[]game.Strike{
+				WrappedStrikeFromStr(bound, geom.Offset{X: 0, Y: 0}, game.StrikeRight, "XXXX"),
+			},
+		},
+		{
+			"removing a cell from a ring",
+			[]game.PlayerMove{
+				{Cell: geom.Offset{X: 0, Y: 0}, Player: game.P1},
+				{Cell: geom.Offset{X: 1, Y: 0}, Player: game.P1},
+				{Cell: geom.Offset{X: 2, Y: 0}, Player: game.P1},
+				{Cell: geom.Offset{X: 3, Y: 0}, Player: game.P1},
+			},
+			[]geom.Offset{{X: 1, Y: 0}},
+			game.StrikeRight,
+			[]game.Strike{
+				WrappedStrikeFromStr(bound, geom.Offset{X: 2, Y: 0}, game.StrikeRight, ".XXX."),
+			},
+		},
+		{
+			"cut a strike at the seam",
+			[]game.PlayerMove{
+				{Cell: geom.Offset{X: 2, Y: 0}, Player: game.P1},
+				{Cell: geom.Offset{X: 3, Y: 0}, Player: game.P1},
+				{Cell: geom.Offset{X: 0, Y: 0}, Player: game.P1},
+			},
+			[]geom.Offset{{X: 3, Y: 0}},
+			game.StrikeRight,
+			[]game.Strike{
+				WrappedStrikeFromStr(bound, geom.Offset{X: 2, Y: 0}, game.StrikeRight, ".X."),
+				WrappedStrikeFromStr(bound, geom.Offset{X: 0, Y: 0}, game.StrikeRight, ".X."),
+			},
+		},
+		{
+			"cut a strike crossing the seam in the middle",
+			[]game.PlayerMove{
+				{Cell: geom.Offset{X: 2, Y: 0}, Player: game.P2},
+				{Cell: geom.Offset{X: 3, Y: 0}, Player: game.P2},
+				{Cell: geom.Offset{X: 0, Y: 0}, Player: game.P2},
+				{Cell: geom.Offset{X: 1, Y: 0}, Player: game.P1},
+			},
+			[]geom.Offset{{X: 3, Y: 0}},
+			game.StrikeRight,
+			[]game.Strike{
+				WrappedStrikeFromStr(bound, geom.Offset{X: 2, Y: 0}, game.StrikeRight, "O."),
+				WrappedStrikeFromStr(bound, geom.Offset{X: 0, Y: 0}, game.StrikeRight, ".O"),
+				WrappedStrikeFromStr(bound, geom.Offset{X: 1, Y: 0}, game.StrikeRight, "X"),
+			},
+		},
+		{
+			"cells outside of the bound are normalized",
+			[]game.PlayerMove{
+				{Cell: geom.Offset{X: -1, Y: 0}, Player: game.P1},
+				{Cell: geom.Offset{X: 4, Y: 3}, Player: game.P1},
+			},
+			nil,
+			game.StrikeRight,
+			[]game.Strike{
+				WrappedStrikeFromStr(bound, geom.Offset{X: 3, Y: 0}, game.StrikeRight, ".XX."),
+			},
+		},
+		{
+			"undoing every move leaves no strikes",
+			[]game.PlayerMove{
+				{Cell: geom.Offset{X: 0, Y: 0}, Player: game.P1},
+				{Cell: geom.Offset{X: 1, Y: 0}, Player: game.P1},
+				{Cell: geom.Offset{X: 2, Y: 0}, Player: game.P1},
+				{Cell: geom.Offset{X: 3, Y: 0}, Player: game.P1},
+			},
+			[]geom.Offset{{X: 3, Y: 0}, {X: 2, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 0}},
+			game.StrikeRight,
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			set := game.NewWrappingStrikeSet(game.StrikeDirs, bound)
+
+			for _, move := range test.moves {
+				set.MakeMove(move.Cell, move.Player)
+			}
+
+			for _, cell := range test.toRevert {
+				td.CmpNoError(t, set.MarkUnoccupied(cell))
+			}
+
+			got := strikesAlong(set.Strikes(), test.dir)
+
+			td.Cmp(t, got, td.Bag(td.Flatten(test.want)))
+		})
+	}
+}
+
+func TestStrikeSetBlocked(t *testing.T) {
+	set := game.NewStrikeSet()
+	td.CmpNoError(t, set.MarkBlocked(geom.Offset{X: 1, Y: 0}))
+
+	set.MakeMove(geom.Offset{X: 0, Y: 0}, game.P1)
+	set.MakeMove(geom.Offset{X: 2, Y: 0}, game.P1)
+
+	got := strikesAlong(set.Strikes(), game.StrikeRight)
+	td.Cmp(t, got, td.Bag(
+		StrikeFromStr(geom.Offset{X: 0, Y: 0}, game.StrikeRight, ".X"),
+		StrikeFromStr(geom.Offset{X: 2, Y: 0}, game.StrikeRight, "X."),
+	))
+
+	td.CmpTrue(t, errors.Is(set.MakeMove(geom.Offset{X: 1, Y: 0}, game.P2), game.ErrCellUnavailable))
+	td.CmpTrue(t, errors.Is(set.MakeMove(geom.Offset{X: 0, Y: 0}, game.P2), game.ErrCellOccupied))
+
+	// Blocked cells stay blocked after undoing the neighbouring moves
+	td.CmpNoError(t, set.MarkUnoccupied(geom.Offset{X: 0, Y: 0}))
+	set.MakeMove(geom.Offset{X: 0, Y: 0}, game.P2)
+
+	got = strikesAlong(set.Strikes(), game.StrikeRight)
+	td.Cmp(t, got, td.Bag(
+		StrikeFromStr(geom.Offset{X: 0, Y: 0}, game.StrikeRight, ".O"),
+		StrikeFromStr(geom.Offset{X: 2, Y: 0}, game.StrikeRight, "X."),
+	))
+
+	td.CmpTrue(t, set.IsBlocked(geom.Offset{X: 1, Y: 0}))
+	td.CmpTrue(t, errors.Is(set.MarkBlocked(geom.Offset{X: 0, Y: 0}), game.ErrCellOccupied))
+	td.CmpTrue(t, errors.Is(set.MarkUnoccupied(geom.Offset{X: 5, Y: 5}), game.ErrCellUnoccupied))
+}
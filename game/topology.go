@@ -0,0 +1,96 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/kitsunemikan/six-purrpurrs/geom"
+)
+
+// Topology is the shape of the board cells and the way they are connected
+type Topology int
+
+const (
+	// SquareTopology has square cells with strikes along rows, columns and both diagonals
+	SquareTopology Topology = iota
+
+	// HexTopology has hexagonal cells addressed with axial coordinates
+	// (see geom.HexToStaggered), with strikes along the three hex axes
+	HexTopology
+)
+
+func (t Topology) String() string {
+	switch t {
+	case SquareTopology:
+		return "square"
+	case HexTopology:
+		return "hex"
+	}
+
+	return fmt.Sprintf("UnknownTopology%d", int(t))
+}
+
+// StrikeDirs returns the axes along which strikes are made
+func (t Topology) StrikeDirs() []StrikeDir {
+	switch t {
+	case SquareTopology:
+		return StrikeDirs
+	case HexTopology:
+		return HexStrikeDirs
+	}
+
+	panic(fmt.Sprintf("topology: strike dirs: unknown topology %v", t))
+}
+
+// hexSymmetries are the square grid symmetries that keep hex cells adjacent
+// in axial coordinates. The rotations by 60 degrees aren't among them
+var hexSymmetries = []geom.Symmetry{
+	geom.Identity,
+	geom.Rotate180,
+	geom.ReflectDiagonal,
+	geom.ReflectAntiDiagonal,
+}
+
+// Symmetries returns the symmetries of the board cells, which keep strikes intact
+func (t Topology) Symmetries() []geom.Symmetry {
+	switch t {
+	case SquareTopology:
+		return geom.Symmetries
+	case HexTopology:
+		return hexSymmetries
+	}
+
+	panic(fmt.Sprintf("topology: symmetries: unknown topology %v", t))
+}
+
+// revealMask returns offsets of the cells that become available around a move
+func (t Topology) revealMask(radius int) []geom.Offset {
+	switch t {
+	case SquareTopology:
+		return generateCircleMask(radius)
+	case HexTopology:
+		return generateHexMask(radius)
+	}
+
+	panic(fmt.Sprintf("topology: reveal mask: unknown topology %v", t))
+}
+
+func generateHexMask(radius int) (mask []geom.Offset) {
+	if radius == 0 {
+		return
+	}
+
+	mask = make([]geom.Offset, 0, 3*radius*(radius+1)+1)
+
+	for dq := -radius; dq <= radius; dq++ {
+		for dr := -radius; dr <= radius; dr++ {
+			ds := geom.Offset{X: dq, Y: dr}
+			if !ds.IsInsideHexagon(radius) {
+				continue
+			}
+
+			mask = append(mask, ds)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,52 @@
+package game
+
+// TurnPolicy decides which player places the stone on each move.
+// Move numbers start from 1, like in GameState.MoveNumber.
+type TurnPolicy interface {
+	PlayerAt(moveNumber, playerCount int) PlayerID
+
+	// StonesLeftAt returns the number of stones, including the one on the given move,
+	// that the current player has yet to place before its turn ends
+	StonesLeftAt(moveNumber int) int
+}
+
+// MultiStoneTurnPolicy lets the first player place FirstTurnStones stones on the very
+// first turn, and then each player in turn places StonesPerTurn stones
+type MultiStoneTurnPolicy struct {
+	FirstTurnStones int
+	StonesPerTurn   int
+}
+
+var (
+	// SingleStoneTurns is the classic rule where players place one stone each turn
+	SingleStoneTurns = MultiStoneTurnPolicy{FirstTurnStones: 1, StonesPerTurn: 1}
+
+	// Connect6Turns is the Connect6 rule where P1 places one stone,
+	// and then each player places two stones per turn
+	Connect6Turns = MultiStoneTurnPolicy{FirstTurnStones: 1, StonesPerTurn: 2}
+)
+
+// turnAt returns the 0-based turn number and the 0-based index of the stone in that turn
+func (tp MultiStoneTurnPolicy) turnAt(moveNumber int) (turn, stone int) {
+	stone = moveNumber - 1
+	if stone < tp.FirstTurnStones {
+		return 0, stone
+	}
+
+	stone -= tp.FirstTurnStones
+	return 1 + stone/tp.StonesPerTurn, stone % tp.StonesPerTurn
+}
+
+func (tp MultiStoneTurnPolicy) PlayerAt(moveNumber, playerCount int) PlayerID {
+	turn, _ := tp.turnAt(moveNumber)
+	return PlayerID(turn % playerCount)
+}
+
+func (tp MultiStoneTurnPolicy) StonesLeftAt(moveNumber int) int {
+	turn, stone := tp.turnAt(moveNumber)
+	if turn == 0 {
+		return tp.FirstTurnStones - stone
+	}
+
+	return tp.StonesPerTurn - stone
+}
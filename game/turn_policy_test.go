@@ -0,0 +1,71 @@
+package game_test
+
+import (
+	"testing"
+
+	"github.com/kitsunemikan/six-purrpurrs/game"
+)
+
+func TestMultiStoneTurnPolicy(t *testing.T) {
+	cases := []struct {
+		desc        string
+		policy      game.TurnPolicy
+		playerCount int
+		wantPlayers []game.PlayerID
+		wantLeft    []int
+	}{
+		{
+			"single stone turns alternate between 2 players",
+			game.SingleStoneTurns,
+			2,
+			[]game.PlayerID{game.P1, game.P2, game.P1, game.P2, game.P1},
+			[]int{1, 1, 1, 1, 1},
+		},
+		{
+			"single stone turns rotate between 3 players",
+			game.SingleStoneTurns,
+			3,
+			[]game.PlayerID{game.P1, game.P2, game.P3, game.P1, game.P2},
+			[]int{1, 1, 1, 1, 1},
+		},
+		{
+			"connect6 turns give P1 a single stone first",
+			game.Connect6Turns,
+			2,
+			[]game.PlayerID{game.P1, game.P2, game.P2, game.P1, game.P1, game.P2, game.P2},
+			[]int{1, 2, 1, 2, 1, 2, 1},
+		},
+		{
+			"connect6 turns with 3 players",
+			game.Connect6Turns,
+			3,
+			[]game.PlayerID{game.P1, game.P2, game.P2, game.P3, game.P3, game.P1, game.P1},
+			[]int{1, 2, 1, 2, 1, 2, 1},
+		},
+		{
+			"long first turn",
+			game.MultiStoneTurnPolicy{FirstTurnStones: 3, StonesPerTurn: 1},
+			2,
+			[]game.PlayerID{game.P1, game.P1, game.P1, game.P2, game.P1},
+			[]int{3, 2, 1, 1, 1},
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.desc, func(t *testing.T) {
+			for i := range test.wantPlayers {
+				moveNumber := i + 1
+
+				gotPlayer := test.policy.PlayerAt(moveNumber, test.playerCount)
+				if gotPlayer != test.wantPlayers[i] {
+					t.Errorf("move %d: got player %v, want %v", moveNumber, gotPlayer, test.wantPlayers[i])
+				}
+
+				gotLeft := test.policy.StonesLeftAt(moveNumber)
+				if gotLeft != test.wantLeft[i] {
+					t.Errorf("move %d: got %d stones left, want %d", moveNumber, gotLeft, test.wantLeft[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package game
+
+import (
+	. "github.com/kitsunemikan/six-purrpurrs/geom"
+)
+
+// View is a read-only snapshot of a game, see GameState.Snapshot. It's what
+// player agents see of the game, so they may think in the background, while
+// the game goes on. A view never changes, and it's safe for concurrent use
+type View interface {
+	PlayerCount() int
+	PlayerToMove() PlayerID
+	StonesLeftInTurn() int
+	MoveNumber() int
+	Result() GameResult
+
+	Topology() Topology
+	TurnPolicy() TurnPolicy
+	Rules() MoveRules
+
+	// Layout returns the layout of the board, or nil. Do not modify the result
+	Layout() *Layout
+
+	MoveLimit() int
+	DrawWithoutWindows() bool
+
+	// VictoryChecker returns a copy of the victory checker of the game
+	VictoryChecker() VictoryChecker
+
+	BoardBound() Rect
+	BorderWidth() int
+	Bounded() bool
+	Wraps() bool
+
+	Cell(pos Offset) CellState
+
+	// UnoccupiedCells and PlayerCells return new sets, which may be modified
+	UnoccupiedCells() map[Offset]struct{}
+	PlayerCells(player PlayerID) map[Offset]struct{}
+
+	Strikes() []Strike
+	StrikeDirs() []StrikeDir
+	MoveHistoryCopy() []PlayerMove
+
+	// CheckMove tells whether the rules allow the move, see GameState.CheckMove
+	CheckMove(pos Offset, player PlayerID) error
+}
+
+// gameView keeps a copy of the game, which is never changed
+type gameView struct {
+	game *GameState
+
+	// result is found once, since the window search caches its outcome
+	result GameResult
+}
+
+// Snapshot copies the game into a view, which stays the same as the game goes on
+func (g *GameState) Snapshot() View {
+	return &gameView{
+		game: &GameState{
+			Board:      g.Board.Clone(),
+			StrikeStat: g.StrikeStat.Clone(),
+
+			playerCount: g.playerCount,
+			layout:      g.layout,
+			turns:       g.turns,
+			rules:       g.rules,
+			victory:     g.victory.Clone(),
+
+			moveLimit:          g.moveLimit,
+			drawWithoutWindows: g.drawWithoutWindows,
+			adjudicated:        g.adjudicated,
+		},
+		result: g.Result(),
+	}
+}
+
+func (v *gameView) PlayerCount() int {
+	return v.game.PlayerCount()
+}
+
+func (v *gameView) PlayerToMove() PlayerID {
+	return v.game.PlayerToMove()
+}
+
+func (v *gameView) StonesLeftInTurn() int {
+	return v.game.StonesLeftInTurn()
+}
+
+func (v *gameView) MoveNumber() int {
+	return v.game.MoveNumber()
+}
+
+func (v *gameView) Result() GameResult {
+	return v.result
+}
+
+func (v *gameView) Topology() Topology {
+	return v.game.Topology()
+}
+
+func (v *gameView) TurnPolicy() TurnPolicy {
+	return v.game.TurnPolicy()
+}
+
+func (v *gameView) Rules() MoveRules {
+	return v.game.Rules()
+}
+
+func (v *gameView) Layout() *Layout {
+	return v.game.Layout()
+}
+
+func (v *gameView) MoveLimit() int {
+	return v.game.MoveLimit()
+}
+
+func (v *gameView) DrawWithoutWindows() bool {
+	return v.game.DrawWithoutWindows()
+}
+
+func (v *gameView) VictoryChecker() VictoryChecker {
+	return v.game.victory.Clone()
+}
+
+func (v *gameView) BoardBound() Rect {
+	return v.game.BoardBound()
+}
+
+func (v *gameView) BorderWidth() int {
+	return v.game.Board.BorderWidth()
+}
+
+func (v *gameView) Bounded() bool {
+	return v.game.Board.Bounded()
+}
+
+func (v *gameView) Wraps() bool {
+	return v.game.Board.Wraps()
+}
+
+func (v *gameView) Cell(pos Offset) CellState {
+	return v.game.Cell(pos)
+}
+
+func (v *gameView) UnoccupiedCells() map[Offset]struct{} {
+	return cellListToSet(v.game.Board.UnoccupiedCellList())
+}
+
+func (v *gameView) PlayerCells(player PlayerID) map[Offset]struct{} {
+	return cellListToSet(v.game.Board.PlayerCellList(player))
+}
+
+func (v *gameView) Strikes() []Strike {
+	return v.game.StrikeStat.Strikes()
+}
+
+func (v *gameView) StrikeDirs() []StrikeDir {
+	dirs := make([]StrikeDir, len(v.game.StrikeStat.Dirs()))
+	copy(dirs, v.game.StrikeStat.Dirs())
+
+	return dirs
+}
+
+func (v *gameView) MoveHistoryCopy() []PlayerMove {
+	return v.game.MoveHistoryCopy()
+}
+
+func (v *gameView) CheckMove(pos Offset, player PlayerID) error {
+	return v.game.CheckMove(pos, player)
+}
@@ -0,0 +1,109 @@
+package game_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"github.com/kitsunemikan/six-purrpurrs/ai"
+	"github.com/kitsunemikan/six-purrpurrs/game"
+	"github.com/kitsunemikan/six-purrpurrs/geom"
+)
+
+func TestSnapshotIsFrozen(t *testing.T) {
+	g := game.NewGame(game.GameOptions{
+		Border:      2,
+		PlayerCount: 2,
+		Victory:     &game.EightDirStrikeVictoryChecker{VictoryLength: 3},
+	})
+
+	td.CmpNoError(t, g.Play(geom.Offset{X: 0, Y: 0}))
+	td.CmpNoError(t, g.Play(geom.Offset{X: 0, Y: 1}))
+
+	view := g.Snapshot()
+	history := g.MoveHistoryCopy()
+	unoccupied := len(g.Board.UnoccupiedCells())
+
+	td.CmpNoError(t, g.Play(geom.Offset{X: 1, Y: 0}))
+	td.CmpNoError(t, g.Play(geom.Offset{X: 1, Y: 1}))
+	td.CmpNoError(t, g.Play(geom.Offset{X: 2, Y: 0}))
+	td.CmpTrue(t, g.Over())
+
+	td.Cmp(t, view.MoveHistoryCopy(), history)
+	td.Cmp(t, view.MoveNumber(), 3)
+	td.Cmp(t, view.PlayerToMove(), game.P1)
+	td.Cmp(t, view.Result(), game.GameResult{})
+	td.Cmp(t, view.Cell(geom.Offset{X: 1, Y: 0}), game.CellUnoccupied)
+	td.Cmp(t, len(view.UnoccupiedCells()), unoccupied)
+	td.Cmp(t, view.PlayerCells(game.P2), map[geom.Offset]struct{}{{X: 0, Y: 1}: {}})
+	td.CmpFalse(t, view.VictoryChecker().Reached())
+
+	// The sets are copies
+	delete(view.UnoccupiedCells(), geom.Offset{X: 1, Y: 0})
+	td.Cmp(t, len(view.UnoccupiedCells()), unoccupied)
+}
+
+// TestAgentsOnSnapshots is meant to be run with the race detector
+func TestSnapshotOptions(t *testing.T) {
+	layout, err := game.ParseLayout(strings.NewReader("..#\n..."))
+	td.CmpNoError(t, err)
+
+	g := game.NewGame(game.GameOptions{
+		Layout:             layout,
+		PlayerCount:        2,
+		Victory:            &game.EightDirStrikeVictoryChecker{VictoryLength: 3},
+		MoveLimit:          4,
+		DrawWithoutWindows: true,
+	})
+
+	view := g.Snapshot()
+	td.Cmp(t, view.Layout(), td.Shallow(layout))
+	td.Cmp(t, view.MoveLimit(), 4)
+	td.CmpTrue(t, view.DrawWithoutWindows())
+}
+
+func TestAgentsOnSnapshots(t *testing.T) {
+	agents := map[string]func() game.PlayerAgent{
+		"random":      ai.NewRandomPlayer,
+		"obstructive": ai.NewObstructivePlayer,
+		"ai": func() game.PlayerAgent {
+			p := ai.NewDefaultAIPlayer()
+			p.SearchDepth = 1
+			return p
+		},
+	}
+
+	for name, newAgent := range agents {
+		t.Run(name, func(t *testing.T) {
+			g := game.NewGame(game.GameOptions{
+				Border:      3,
+				PlayerCount: 2,
+				Victory:     &game.EightDirStrikeVictoryChecker{VictoryLength: 6},
+			})
+
+			agent := newAgent()
+			for i := 0; i < 10 && !g.Over(); i++ {
+				view := g.Snapshot()
+
+				var wg sync.WaitGroup
+				var move geom.Offset
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					move = agent.MakeMove(view)
+				}()
+
+				// The game changes while the agent thinks, and is then taken back
+				g.MarkCell(geom.Offset{X: 100 + i, Y: 100}, g.PlayerToMove())
+				g.StrikeStat.Strikes()
+				g.UndoLastMove()
+
+				wg.Wait()
+
+				td.CmpNoError(t, g.Play(move))
+			}
+		})
+	}
+}
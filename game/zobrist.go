@@ -0,0 +1,23 @@
+package game
+
+import (
+	. "github.com/kitsunemikan/six-purrpurrs/geom"
+)
+
+// zobristKey is the key of the cell and player, whose XOR with the keys of
+// the other stones makes up the hash of a board. The boards are unbounded, so
+// the keys are mixed from the coordinates instead of being kept in a table,
+// which makes them the same across runs and free to compute concurrently
+func zobristKey(cell Offset, player PlayerID) uint64 {
+	at := uint64(uint32(cell.X)) | uint64(uint32(cell.Y))<<32
+	return splitmix64(splitmix64(at) + uint64(player))
+}
+
+// splitmix64 is the finalizer of the SplitMix64 generator, which spreads
+// the bits of the input all over the output
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
@@ -17,7 +17,6 @@ type BoardModel struct {
 	selection Offset
 
 	SelectionVisible bool
-	CurrentPlayer    game.PlayerID
 
 	ForcedHighlight map[Offset]lipgloss.Style
 }
@@ -33,17 +32,89 @@ func NewBoardModel(cameraSize Offset, trackDepth int) BoardModel {
 	}
 }
 
+func (m BoardModel) isHex() bool {
+	return m.Board.Topology() == game.HexTopology
+}
+
+// toDisplay converts board coordinates into the display coordinates used by the camera
+func (m BoardModel) toDisplay(cell Offset) Offset {
+	if m.isHex() {
+		return HexToStaggered(cell)
+	}
+
+	return cell
+}
+
+func (m BoardModel) fromDisplay(pos Offset) Offset {
+	if m.isHex() {
+		return StaggeredToHex(pos)
+	}
+
+	return pos
+}
+
+// displayBound returns the board bound in display coordinates
+func (m BoardModel) displayBound() Rect {
+	if m.isHex() {
+		return HexRectToStaggered(m.Board.BoardBound())
+	}
+
+	return m.Board.BoardBound()
+}
+
+// snapCamera keeps the camera on the board. Wrapping boards have
+// no edges, so the camera may go anywhere
+func (m BoardModel) snapCamera(camera Camera) Camera {
+	if m.Board.Wraps() {
+		return camera
+	}
+
+	return camera.SnapIntoRect(m.displayBound())
+}
+
+// MoveSelectionBy moves the selection by ds in board coordinates.
+// On wrapping boards the selection may leave the bound to cross the seam
 func (m BoardModel) MoveSelectionBy(ds Offset) BoardModel {
 	cameraBound := m.Board.BoardBound()
 	newSelection := m.selection.Add(ds)
 
-	if newSelection.IsInsideRect(cameraBound) {
+	if m.Board.Wraps() || newSelection.IsInsideRect(cameraBound) {
 		m.selection = newSelection
 	}
 
 	return m
 }
 
+// MoveSelectionVertically moves the selection dy rows up or down. On hex boards
+// the selection zig-zags between the two neighbours to stay in the same display column
+func (m BoardModel) MoveSelectionVertically(dy int) BoardModel {
+	target := m.fromDisplay(m.toDisplay(m.selection).AddXY(0, dy))
+	return m.MoveSelectionBy(target.Sub(m.selection))
+}
+
+// MoveSelectionDiagonally moves the selection to the diagonal neighbour in the
+// display direction (dx, dy), where both dx and dy are either -1 or 1.
+// On hex boards these are the four neighbours in the adjacent rows
+func (m BoardModel) MoveSelectionDiagonally(dx, dy int) BoardModel {
+	if !m.isHex() {
+		return m.MoveSelectionBy(Offset{X: dx, Y: dy})
+	}
+
+	display := m.toDisplay(m.selection)
+
+	// Odd rows are shifted to the right, so the upper and lower neighbours
+	// of an odd row cell are either at the same column or to the right
+	column := display.X
+	if display.Y&1 == 1 && dx > 0 {
+		column++
+	} else if display.Y&1 == 0 && dx < 0 {
+		column--
+	}
+
+	target := m.fromDisplay(Offset{X: column, Y: display.Y + dy})
+	return m.MoveSelectionBy(target.Sub(m.selection))
+}
+
 func (m BoardModel) MoveSelectionTo(pos Offset) BoardModel {
 	cameraBound := m.Board.BoardBound()
 
@@ -55,44 +126,58 @@ func (m BoardModel) MoveSelectionTo(pos Offset) BoardModel {
 }
 
 func (m BoardModel) MoveCameraBy(ds Offset) BoardModel {
-	m.camera = m.camera.Move(ds).SnapIntoRect(m.Board.BoardBound())
+	m.camera = m.snapCamera(m.camera.Move(ds))
 
 	return m
 }
 
 func (m BoardModel) NudgeCameraTo(pos Offset) BoardModel {
-	m.camera = m.camera.NudgeTo(pos).SnapIntoRect(m.Board.BoardBound())
+	m.camera = m.snapCamera(m.camera.NudgeTo(m.toDisplay(pos)))
 	return m
 }
 
 func (m BoardModel) SnapSelectionIntoCamera() BoardModel {
-	m.selection = m.selection.SnapIntoRect(m.camera.InnerView())
+	m.selection = m.fromDisplay(m.toDisplay(m.selection).SnapIntoRect(m.camera.InnerView()))
 
 	return m
 }
 
 func (m BoardModel) NudgeToSelection() BoardModel {
-	m.camera = m.camera.NudgeTo(m.selection).SnapIntoRect(m.Board.BoardBound())
+	m.camera = m.snapCamera(m.camera.NudgeTo(m.toDisplay(m.selection)))
 	return m
 }
 
 func (m BoardModel) CenterOnBoard() BoardModel {
-	m.camera = m.camera.SnapIntoRect(m.Board.BoardBound())
+	m.camera = m.camera.SnapIntoRect(m.displayBound())
 	return m
 }
 
+// Selection returns the selected cell, which is always on the board
 func (m BoardModel) Selection() Offset {
-	return m.selection
+	return m.Board.Normalize(m.selection)
 }
 
 func (m BoardModel) ModelDimensions() Offset {
 	// 2 * camera dimensions, because we artificially stretch
 	// the board, so that it appears more square when rendered
-	return Offset{X: 2 * m.camera.View.W, Y: 2 * m.camera.View.H}
+	dimensions := Offset{X: 2 * m.camera.View.W, Y: 2 * m.camera.View.H}
+
+	// Staggered rows take an extra column
+	if m.isHex() {
+		dimensions.X++
+	}
+
+	return dimensions
 }
 
 func (m BoardModel) View() string {
-	cliBoard := m.Theme.BoardToText(m.Board.AllCells(), m.camera.View)
+	cliBoard := make(map[Offset]string, m.camera.View.Area())
+	for y := 0; y < m.camera.View.H; y++ {
+		for x := 0; x < m.camera.View.W; x++ {
+			cell := m.fromDisplay(m.camera.View.ToWorldXY(x, y))
+			cliBoard[cell] = m.Theme.StateToText(m.Board.Cell(cell))
+		}
+	}
 
 	// Repeated application of lipgloss render will produce incorrect results
 	// Instead, we'll store the exact style for the cell in a map
@@ -103,26 +188,45 @@ func (m BoardModel) View() string {
 		styledCells[cell] = style
 	}
 
-	// Apply styles
+	// Apply styles. Cells of wrapping boards are repeated past the seam,
+	// so the highlights are looked up for the cells they repeat
 	for pos, str := range cliBoard {
-		style, special := styledCells[pos]
+		style, special := styledCells[m.Board.Normalize(pos)]
 		if special {
 			cliBoard[pos] = style.Render(str)
 			continue
 		}
 
 		cellState := m.Board.Cell(pos)
-		if cellState == game.CellUnavailable || cellState == game.CellUnoccupied {
+		if cellState == game.CellUnavailable {
+			continue
+		}
+
+		if cellState == game.CellBlocked {
+			cliBoard[pos] = m.Theme.BlockedCellStyle.Render(str)
 			continue
 		}
 
-		cliBoard[pos] = m.Theme.PlayerCellStyles[cellState].Render(str)
+		if cellState == game.CellUnoccupied {
+			if m.Board.Wraps() && !pos.IsInsideRect(m.Board.BoardBound()) {
+				cliBoard[pos] = m.Theme.WrappedCellStyle.Render(str)
+			}
+
+			continue
+		}
+
+		cliBoard[pos] = m.Theme.PlayerCellStyle(game.PlayerID(cellState)).Render(str)
 	}
 
 	var view strings.Builder
 	for y := 0; y < m.camera.View.H; y++ {
+		// Odd rows of hex boards are shifted by half a cell, which is one character
+		if m.isHex() && m.camera.View.ToWorldXY(0, y).Y&1 == 1 {
+			view.WriteByte(' ')
+		}
+
 		for x := 0; x < m.camera.View.W; x++ {
-			curCell := m.camera.View.ToWorldXY(x, y)
+			curCell := m.fromDisplay(m.camera.View.ToWorldXY(x, y))
 
 			leftSide := " "
 			rightSide := ""
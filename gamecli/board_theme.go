@@ -10,13 +10,25 @@ import (
 type BoardTheme struct {
 	InvalidCell    string
 	UnoccupiedCell string
-	PlayerCells    []string
+	BlockedCell    string
 
+	// PlayerCells holds an avatar for each player indexed by its PlayerID.
+	// There must be at least as many avatars as there are players
+	PlayerCells []string
+
+	// PlayerCellStyles are applied to player avatars cyclically, so
+	// there may be less styles than players
 	PlayerCellStyles []lipgloss.Style
 
 	CandidateCellStyle lipgloss.Style
 	VictoryCellStyle   lipgloss.Style
 	LastEnemyCellStyle lipgloss.Style
+	ForbiddenCellStyle lipgloss.Style
+	BlockedCellStyle   lipgloss.Style
+
+	// WrappedCellStyle is applied to unoccupied cells that are repeated
+	// past the seam of a wrapping board
+	WrappedCellStyle lipgloss.Style
 
 	SelectionInactiveStyle lipgloss.Style
 }
@@ -34,25 +46,44 @@ func ApplyCyclingStyles(text []string, styles []lipgloss.Style) []string {
 	return styledText
 }
 
+func (ts *BoardTheme) PlayerCellStyle(player game.PlayerID) lipgloss.Style {
+	return ts.PlayerCellStyles[int(player)%len(ts.PlayerCellStyles)]
+}
+
+// CellToText returns the text of a single board cell
+func (ts *BoardTheme) CellToText(board map[Offset]game.CellState, cell Offset) string {
+	state, present := board[cell]
+	if !present {
+		return ts.InvalidCell
+	}
+
+	return ts.StateToText(state)
+}
+
+// StateToText returns the text of a cell in the given state
+func (ts *BoardTheme) StateToText(state game.CellState) string {
+	if state == game.CellUnavailable {
+		return ts.InvalidCell
+	}
+
+	if state == game.CellUnoccupied {
+		return ts.UnoccupiedCell
+	}
+
+	if state == game.CellBlocked {
+		return ts.BlockedCell
+	}
+
+	return ts.PlayerCells[state]
+}
+
 func (ts *BoardTheme) BoardToText(board map[Offset]game.CellState, camera Rect) map[Offset]string {
 	cliBoard := make(map[Offset]string, camera.Area())
 
 	for x := 0; x < camera.W; x++ {
 		for y := 0; y < camera.H; y++ {
 			curCell := camera.ToWorldXY(x, y)
-
-			state, present := board[curCell]
-			if !present {
-				cliBoard[curCell] = ts.InvalidCell
-				continue
-			}
-
-			if state == game.CellUnoccupied {
-				cliBoard[curCell] = ts.UnoccupiedCell
-				continue
-			}
-
-			cliBoard[curCell] = ts.PlayerCells[state]
+			cliBoard[curCell] = ts.CellToText(board, curCell)
 		}
 	}
 	return cliBoard
@@ -9,12 +9,45 @@ import (
 
 // GameModel is a bubble that displays game board
 // and additionally highlights:
+// * Cells forbidden for the current player
 // * Strike candidates
 // * Latest marked cell
 // * A winning strike
 type GameModel struct {
 	Game  *game.GameState
 	Board BoardModel
+
+	// forbidden keeps the forbidden cells between the views, it may be nil
+	forbidden *forbiddenCells
+}
+
+// forbiddenCells remembers the cells forbidden for the player to move. Finding them
+// tries every unoccupied cell against the rules, so it's done once per move.
+// It's shared between the copies of the model that owns it
+type forbiddenCells struct {
+	moveNumber int
+	hash       uint64
+	cells      []Offset
+}
+
+func newForbiddenCells() *forbiddenCells {
+	return &forbiddenCells{}
+}
+
+// of returns the forbidden cells of the position. The move number and the hash of
+// the stones tell the positions apart, since a move may be undone and replaced
+func (c *forbiddenCells) of(g *game.GameState) []Offset {
+	if c == nil {
+		return g.ForbiddenCells(g.PlayerToMove())
+	}
+
+	if c.moveNumber != g.MoveNumber() || c.hash != g.Board.Hash() {
+		c.moveNumber = g.MoveNumber()
+		c.hash = g.Board.Hash()
+		c.cells = g.ForbiddenCells(g.PlayerToMove())
+	}
+
+	return c.cells
 }
 
 func (m GameModel) View() string {
@@ -22,10 +55,14 @@ func (m GameModel) View() string {
 	// Instead, we'll store the exact style for the cell in a map
 	styledCells := make(map[Offset]lipgloss.Style)
 
+	for _, cell := range m.forbidden.of(m.Game) {
+		styledCells[cell] = m.Board.Theme.ForbiddenCellStyle
+	}
+
 	// Highlight candidates, if selection is visible
 	selection := m.Board.Selection()
 	if m.Board.SelectionVisible && m.Game.Cell(selection) == game.CellUnoccupied {
-		candidates := m.Game.CandidatesAroundFor(selection, m.Board.CurrentPlayer)
+		candidates := m.Game.CandidatesAroundFor(selection, m.Game.PlayerToMove())
 
 		for _, cell := range candidates {
 			styledCells[cell] = m.Board.Theme.CandidateCellStyle
@@ -33,8 +70,7 @@ func (m GameModel) View() string {
 	}
 
 	// Highlight last enemy cell
-	if m.Game.MoveNumber() > 1 {
-		latestMove := m.Game.LatestMove()
+	if latestMove, err := m.Game.LatestMove(); err == nil {
 		styledCells[latestMove.Cell] = m.Board.Theme.LastEnemyCellStyle
 	}
 
@@ -18,6 +18,11 @@ type GameOverModel struct {
 	Board    BoardModel
 	Help     help.Model
 	GameTime time.Duration
+
+	// Players are passed to the replay, see ReplayModelOptions
+	Players []game.PlayerAgent
+
+	forbidden *forbiddenCells
 }
 
 func (m GameOverModel) Init() tea.Cmd {
@@ -37,6 +42,8 @@ func (m GameOverModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				Board:  m.Board,
 				Help:   m.Help,
 				Parent: m,
+
+				Players: m.Players,
 			})
 
 			cmd := replayModel.Init()
@@ -54,20 +61,24 @@ func (m GameOverModel) View() string {
 	var view strings.Builder
 
 	gameModel := GameModel{
-		Game:  m.Game,
-		Board: m.Board,
+		Game:      m.Game,
+		Board:     m.Board,
+		forbidden: m.forbidden,
 	}
 
 	view.WriteString(gameModel.View())
 	view.WriteByte('\n')
 
-	if m.Game.VictoriousStrike() == nil {
+	result := m.Game.Result()
+	if result.IsDraw() {
 		view.WriteString("A draw...")
 	} else {
-		view.WriteString(m.Board.Theme.PlayerCells[m.Game.Winner()])
+		view.WriteString(m.Board.Theme.PlayerCells[result.Winner])
 		view.WriteString(" wins!")
 	}
 
+	view.WriteString(fmt.Sprintf("\nOutcome: %v", result.Outcome))
+
 	view.WriteString(fmt.Sprintf("\n\nTotal number of moves made: %d\nTotal time: %v\n\n", m.Game.MoveNumber()-1, m.GameTime))
 
 	view.WriteString(m.Help.View(keymap.GameOver))
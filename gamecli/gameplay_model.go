@@ -1,6 +1,10 @@
 package gamecli
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
 	"strings"
 	"time"
 
@@ -16,25 +20,137 @@ import (
 // A bubbletea event
 type PlayerMoveMsg struct {
 	ChosenCell Offset
+
+	// Err is why the agent failed to make the move
+	Err error
+
+	// moveID tells apart the moves of cancelled requests
+	moveID int
+}
+
+// A bubbletea event
+type OpeningDecisionMsg struct {
+	Choice game.OpeningChoice
+
+	// Err is why the agent failed to decide
+	Err error
+
+	// moveID tells apart the decisions of cancelled requests
+	moveID int
+}
+
+// A bubbletea event
+type DrawResponseMsg struct {
+	Accepted bool
+
+	// Err is why the agent failed to respond
+	Err error
+
+	// responseID tells apart the responses of cancelled requests
+	responseID int
+}
+
+type clockTickMsg time.Time
+
+// clockTickInterval is how often the clocks are redrawn and checked for timeouts
+const clockTickInterval = 100 * time.Millisecond
+
+// pendingMove is the move, decision or draw response being awaited from a player
+// agent. It's shared by the copies of the model, so that any of them may cancel it
+type pendingMove struct {
+	id     int
+	cancel context.CancelFunc
+}
+
+// next starts awaiting another request. Its id tells it apart from the cancelled ones
+func (p *pendingMove) next() (context.Context, int) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.id++
+	p.cancel = cancel
+
+	return ctx, p.id
+}
+
+// stop cancels the awaited request, and reports whether there was one
+func (p *pendingMove) stop() bool {
+	if p.cancel == nil {
+		return false
+	}
+
+	p.cancel()
+	p.cancel = nil
+	return true
+}
+
+// drawOffer is a draw offered by a player, that the other players are yet to respond to
+type drawOffer struct {
+	offeredBy game.PlayerID
+
+	// pendingSeats are the seats that haven't responded yet, in turn order
+	pendingSeats []int
 }
 
 type GameplayModelConfig struct {
 	Game    *game.GameState
 	Players []game.PlayerAgent
 
+	// Opening is an opening protocol for two-player games, optional
+	Opening *game.Opening
+
+	// Clock keeps the time of two-player games, optional. It starts once the opening is over
+	Clock *game.Clock
+
+	// Save writes the game to a file, when the save key is pressed, optional
+	Save func(g *game.GameState, opening *game.Opening) error
+
 	Theme      *BoardTheme
 	ScreenSize Offset
 	TrackDepth int
 }
 
+// maxRejectedMoves is the number of invalid moves in a row, after which an agent,
+// which isn't a local player, is no longer asked to move. An agent repeating the same
+// invalid move would otherwise be asked forever, while the game seems to hang
+const maxRejectedMoves = 3
+
 type GameplayModel struct {
-	Game  *game.GameState
-	board BoardModel
-	help  help.Model
+	Game      *game.GameState
+	board     BoardModel
+	forbidden *forbiddenCells
+	help      help.Model
 
 	MoveCommitted bool
-	CurrentPlayer game.PlayerID
-	Players       []game.PlayerAgent
+
+	// Players are indexed by their opening seats, which are the same as
+	// PlayerIDs unless the colours were swapped during the opening
+	Players []game.PlayerAgent
+	opening *game.Opening
+	clock   *game.Clock
+	save    func(*game.GameState, *game.Opening) error
+
+	// moveErr is the reason the latest move was rejected
+	moveErr error
+
+	// rejectedMoves counts the invalid moves the agent to move has made in a row.
+	// Once it reaches maxRejectedMoves, the game is stalled, and stalled tells why
+	rejectedMoves int
+	stalled       error
+
+	pending     *pendingMove
+	pendingDraw *pendingMove
+
+	// stopFollowing stops sending the game events to the agents following the game
+	stopFollowing func()
+
+	// undoFrom is the number of the first move, that may be taken back.
+	// The moves of the opening and the moves made before the gameplay are kept
+	undoFrom int
+
+	drawOffer    *drawOffer
+	drawDeclined bool
+
+	saved   bool
+	saveErr error
 
 	gameStartedAt time.Time
 }
@@ -48,47 +164,343 @@ func NewGameplayModel(config GameplayModelConfig) GameplayModel {
 		panic("new gameplay model: board theme is nil")
 	}
 
-	if len(config.Players) == 0 {
-		panic("new gameplay model: no player agents specified")
+	if len(config.Players) != config.Game.PlayerCount() {
+		panic(fmt.Sprintf("new gameplay model: got %d player agents for a game of %d players", len(config.Players), config.Game.PlayerCount()))
+	}
+
+	if len(config.Theme.PlayerCells) < config.Game.PlayerCount() {
+		panic(fmt.Sprintf("new gameplay model: board theme has %d player avatars for a game of %d players", len(config.Theme.PlayerCells), config.Game.PlayerCount()))
 	}
 
 	if config.ScreenSize.IsZero() {
 		panic("new gameplay model: zero screen size")
 	}
 
+	if config.Opening == nil {
+		config.Opening = game.NewOpening(game.NoOpening)
+	}
+
+	if config.Opening.Protocol() != game.NoOpening && config.Game.PlayerCount() != 2 {
+		panic(fmt.Sprintf("new gameplay model: opening protocol %v requires 2 players, got %d", config.Opening.Protocol(), config.Game.PlayerCount()))
+	}
+
+	if config.Clock != nil && config.Game.PlayerCount() != 2 {
+		panic(fmt.Sprintf("new gameplay model: clocks require 2 players, got %d", config.Game.PlayerCount()))
+	}
+
 	board := NewBoardModel(config.ScreenSize, config.TrackDepth)
 	board.Board = config.Game.Board
 	board.Theme = config.Theme
-	board.CurrentPlayer = game.P1
 
 	help := help.New()
 	help.Styles = HelpStyle
 	return GameplayModel{
-		Game:    config.Game,
-		Players: config.Players,
-		board:   board,
-		help:    help,
+		Game:      config.Game,
+		Players:   config.Players,
+		opening:   config.Opening,
+		clock:     config.Clock,
+		save:      config.Save,
+		board:     board,
+		forbidden: newForbiddenCells(),
+		help:      help,
+		pending:   &pendingMove{},
+
+		pendingDraw: &pendingMove{},
 
-		CurrentPlayer: game.P1,
+		stopFollowing: game.StartFollowing(config.Game, config.Players),
+
+		undoFrom: config.Game.MoveNumber(),
 
 		gameStartedAt: time.Now(),
 	}
 }
 
-func (m *GameplayModel) AwaitMove(player game.PlayerID) tea.Cmd {
+// activeSeat returns the seat of the player agent that should act next
+func (m *GameplayModel) activeSeat() int {
+	if !m.opening.Done() {
+		return m.opening.Step().Seat
+	}
+
+	return m.opening.SeatOf(m.Game.PlayerToMove())
+}
+
+func (m *GameplayModel) awaitingDecision() bool {
+	return m.opening.Step().Kind == game.OpeningDecide
+}
+
+// AwaitMove waits for the active player agent to either make a move,
+// or a decision during the opening
+func (m *GameplayModel) AwaitMove() tea.Cmd {
+	agent := m.Players[m.activeSeat()]
+
+	// The agent thinks in the background, while the game may be changed by
+	// the UI, e.g., if the clock runs out, so the agent gets a snapshot
+	view := m.Game.Snapshot()
+
+	m.cancelMove()
+	ctx, moveID := m.pending.next()
+
+	if m.awaitingDecision() {
+		step := m.opening.Step()
+		me := m.opening.PlayerOf(step.Seat)
+
+		return func() tea.Msg {
+			choice, err := decideOpening(ctx, agent, view, me, step.Choices)
+			return OpeningDecisionMsg{Choice: choice, Err: err, moveID: moveID}
+		}
+	}
+
+	return func() tea.Msg {
+		move, err := game.WithContext(agent).MakeMoveContext(ctx, view)
+		return PlayerMoveMsg{ChosenCell: move, Err: err, moveID: moveID}
+	}
+}
+
+// decideOpening asks the agent for an opening decision. Agents that
+// can't decide keep their colour
+func decideOpening(ctx context.Context, agent game.PlayerAgent, view game.View, me game.PlayerID, choices []game.OpeningChoice) (game.OpeningChoice, error) {
+	switch decider := agent.(type) {
+	case game.ContextOpeningDecider:
+		return decider.DecideOpeningContext(ctx, view, me, choices)
+	case game.OpeningDecider:
+		return decider.DecideOpening(view, me, choices), nil
+	}
+
+	return game.OpeningKeepColor, nil
+}
+
+// respondToDraw asks the agent whether it accepts the draw. Agents that
+// can't respond decline
+func respondToDraw(ctx context.Context, agent game.PlayerAgent, view game.View, me, offeredBy game.PlayerID) (bool, error) {
+	switch responder := agent.(type) {
+	case game.ContextDrawResponder:
+		return responder.RespondToDrawContext(ctx, view, me, offeredBy)
+	case game.DrawResponder:
+		return responder.RespondToDraw(view, me, offeredBy), nil
+	}
+
+	return false, nil
+}
+
+// cancelMove cancels the move or the decision being awaited, if any. The answers
+// committed by local players for it are dropped, since they're no longer awaited
+func (m *GameplayModel) cancelMove() {
+	if m.pending.stop() {
+		m.dropCommitted()
+	}
+}
+
+// cancelDrawResponse cancels the draw response being awaited, if any
+func (m *GameplayModel) cancelDrawResponse() {
+	if m.pendingDraw.stop() {
+		m.dropCommitted()
+	}
+}
+
+func (m *GameplayModel) dropCommitted() {
+	for _, agent := range m.Players {
+		if localPlayer, local := agent.(*LocalPlayer); local {
+			localPlayer.dropCommitted()
+		}
+	}
+}
+
+// undoTarget returns the move number to go back to, for the local player to move
+// to replay their last turn. The moves of the other players since then are taken
+// back as well. False, if the player has no turn to take back
+func (m *GameplayModel) undoTarget() (int, bool) {
+	if !m.opening.Done() || m.drawOffer != nil || m.MoveCommitted || !m.IsLocalPlayerTurn() {
+		return 0, false
+	}
+
+	me := m.Game.PlayerToMove()
+	history := m.Game.MoveHistoryCopy()
+
+	last := len(history)
+	for last > 0 && history[last-1].Player != me {
+		last--
+	}
+
+	// A turn of several stones is taken back whole
+	first := last
+	for first > 0 && history[first-1].Player == me {
+		first--
+	}
+
+	target := first + 1
+	if first == last || target < m.undoFrom {
+		return 0, false
+	}
+
+	return target, true
+}
+
+// undo takes back the moves up to the last turn of the local player to move,
+// see undoTarget, and awaits their move again. The awaited move is cancelled
+func (m GameplayModel) undo(target int) (tea.Model, tea.Cmd) {
+	m.cancelMove()
+
+	m.moveErr = nil
+	for m.Game.MoveNumber() > target {
+		if err := m.Game.UndoLastMove(); err != nil {
+			m.moveErr = err
+			break
+		}
+	}
+
+	m.MoveCommitted = false
+	m.rejectedMoves = 0
+	m.drawDeclined = false
+	m.saved = false
+
+	m.switchClock(time.Now())
+
+	return m, m.AwaitMove()
+}
+
+// rejectMove shows why the move was rejected, and awaits another one.
+// Agents other than local players are given up on after maxRejectedMoves
+func (m GameplayModel) rejectMove(err error) (tea.Model, tea.Cmd) {
+	m.moveErr = err
+	m.MoveCommitted = false
+
+	if !m.IsLocalPlayerTurn() {
+		m.rejectedMoves++
+	}
+
+	if m.rejectedMoves >= maxRejectedMoves {
+		m.stalled = fmt.Errorf("player %s made %d invalid moves in a row, so the game is stopped", m.board.Theme.PlayerCells[m.Game.PlayerToMove()], m.rejectedMoves)
+		return m, nil
+	}
+
+	return m, m.AwaitMove()
+}
+
+// openingDone makes the moves after the opening undoable
+func (m *GameplayModel) openingDone() {
+	if m.opening.Done() {
+		m.undoFrom = m.Game.MoveNumber()
+	}
+}
+
+// AwaitDrawResponse asks the next player to respond to the draw offer
+func (m *GameplayModel) AwaitDrawResponse() tea.Cmd {
+	seat := m.drawOffer.pendingSeats[0]
+	agent := m.Players[seat]
+	me := m.opening.PlayerOf(seat)
+	offeredBy := m.drawOffer.offeredBy
+	view := m.Game.Snapshot()
+
+	m.cancelDrawResponse()
+	ctx, responseID := m.pendingDraw.next()
+
 	return func() tea.Msg {
-		move := m.Players[player].MakeMove(m.Game)
-		return PlayerMoveMsg{move}
+		accept, err := respondToDraw(ctx, agent, view, me, offeredBy)
+		return DrawResponseMsg{Accepted: accept, Err: err, responseID: responseID}
+	}
+}
+
+// offerDraw asks all the other players in turn order, whether they accept a draw
+func (m *GameplayModel) offerDraw() tea.Cmd {
+	offeredBy := m.Game.PlayerToMove()
+
+	offer := &drawOffer{offeredBy: offeredBy}
+	for p := offeredBy.NextPlayer(m.Game.PlayerCount()); p != offeredBy; p = p.NextPlayer(m.Game.PlayerCount()) {
+		offer.pendingSeats = append(offer.pendingSeats, m.opening.SeatOf(p))
+	}
+
+	m.drawOffer = offer
+	m.drawDeclined = false
+	return m.AwaitDrawResponse()
+}
+
+func (m *GameplayModel) isLocalDrawResponder() bool {
+	if m.drawOffer == nil {
+		return false
+	}
+
+	_, local := m.Players[m.drawOffer.pendingSeats[0]].(*LocalPlayer)
+	return local
+}
+
+// switchClock runs the time of the player to move, if it isn't running yet
+func (m *GameplayModel) switchClock(now time.Time) {
+	if m.clock == nil || !m.opening.Done() || m.Game.Over() {
+		return
+	}
+
+	player, running := m.clock.Running()
+	if running && player == m.Game.PlayerToMove() {
+		return
+	}
+
+	// Timeouts are checked before the moves are made
+	if running {
+		m.clock.Stop(now)
+	}
+
+	m.clock.Start(m.Game.PlayerToMove(), now)
+}
+
+// timedOut reports whether the player to move has run out of time
+func (m *GameplayModel) timedOut(now time.Time) bool {
+	if m.clock == nil {
+		return false
+	}
+
+	player, running := m.clock.Running()
+	return running && m.clock.Flagged(player, now)
+}
+
+func (m GameplayModel) loseOnTime() (tea.Model, tea.Cmd) {
+	player, _ := m.clock.Running()
+	if err := m.Game.LoseOnTime(player); err != nil {
+		log.Printf("gameplay: rejected timeout: %v", err)
+		return m, nil
+	}
+
+	return m.gameOver(), nil
+}
+
+func tickClock() tea.Cmd {
+	return tea.Tick(clockTickInterval, func(t time.Time) tea.Msg {
+		return clockTickMsg(t)
+	})
+}
+
+func (m GameplayModel) gameOver() GameOverModel {
+	m.cancelMove()
+	m.cancelDrawResponse()
+	m.stopFollowing()
+
+	if m.clock != nil {
+		if _, running := m.clock.Running(); running {
+			m.clock.Stop(time.Now())
+		}
+	}
+
+	return GameOverModel{
+		Game:      m.Game,
+		Board:     m.board,
+		Help:      m.help,
+		GameTime:  time.Now().Sub(m.gameStartedAt),
+		Players:   m.Players,
+		forbidden: m.forbidden,
 	}
 }
 
 func (m *GameplayModel) IsLocalPlayerTurn() bool {
-	_, local := m.Players[m.CurrentPlayer].(*LocalPlayer)
+	_, local := m.Players[m.activeSeat()].(*LocalPlayer)
 	return local
 }
 
 func (m GameplayModel) Init() tea.Cmd {
-	return m.AwaitMove(m.CurrentPlayer)
+	if m.clock == nil {
+		return m.AwaitMove()
+	}
+
+	m.switchClock(time.Now())
+	return tea.Batch(m.AwaitMove(), tickClock())
 }
 
 func (m GameplayModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -103,13 +515,103 @@ func (m GameplayModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case key.Matches(msg, keymap.Gameplay.Help):
 			m.help.ShowAll = !m.help.ShowAll
 		case key.Matches(msg, keymap.Gameplay.Quit):
+			m.cancelMove()
+			m.cancelDrawResponse()
+			m.stopFollowing()
 			return m, tea.Quit
+
+		case key.Matches(msg, keymap.Gameplay.Undo):
+			target, ok := m.undoTarget()
+			if !ok {
+				return m, nil
+			}
+
+			return m.undo(target)
+		case key.Matches(msg, keymap.Gameplay.Save):
+			if m.save == nil {
+				return m, nil
+			}
+
+			m.saveErr = m.save(m.Game, m.opening)
+			m.saved = m.saveErr == nil
+			return m, nil
+		}
+
+		if m.drawOffer != nil {
+			if !m.isLocalDrawResponder() || m.MoveCommitted {
+				return m, nil
+			}
+
+			var accept bool
+			switch {
+			case key.Matches(msg, keymap.Gameplay.AcceptDraw):
+				accept = true
+			case key.Matches(msg, keymap.Gameplay.DeclineDraw):
+				accept = false
+			default:
+				return m, nil
+			}
+
+			localPlayer := m.Players[m.drawOffer.pendingSeats[0]].(*LocalPlayer)
+			localPlayer.CommitDrawResponse(accept)
+
+			m.MoveCommitted = true
+			return m, nil
 		}
 
 		if !m.IsLocalPlayerTurn() {
 			break
 		}
 
+		if m.opening.Done() && !m.MoveCommitted {
+			switch {
+			case key.Matches(msg, keymap.Gameplay.Resign):
+				err := m.Game.Resign(m.Game.PlayerToMove())
+				if errors.Is(err, game.ErrNotTwoPlayers) {
+					m.moveErr = fmt.Errorf("resigning is only possible in two-player games, this one has %d players", m.Game.PlayerCount())
+					return m, nil
+				}
+
+				if err != nil {
+					m.moveErr = err
+					return m, nil
+				}
+
+				return m.gameOver(), nil
+
+			case key.Matches(msg, keymap.Gameplay.OfferDraw):
+				return m, m.offerDraw()
+			}
+		}
+
+		if m.awaitingDecision() {
+			if m.MoveCommitted {
+				return m, nil
+			}
+
+			var choice game.OpeningChoice
+			switch {
+			case key.Matches(msg, keymap.Gameplay.KeepColor):
+				choice = game.OpeningKeepColor
+			case key.Matches(msg, keymap.Gameplay.SwapColor):
+				choice = game.OpeningSwapColor
+			case key.Matches(msg, keymap.Gameplay.PlaceMore):
+				choice = game.OpeningPlaceMore
+			default:
+				return m, nil
+			}
+
+			if !hasOpeningChoice(m.opening.Step().Choices, choice) {
+				return m, nil
+			}
+
+			localPlayer := m.Players[m.activeSeat()].(*LocalPlayer)
+			localPlayer.CommitDecision(choice)
+
+			m.MoveCommitted = true
+			return m, nil
+		}
+
 		switch {
 		case key.Matches(msg, keymap.Gameplay.Left):
 			m.board = m.board.MoveSelectionBy(Offset{X: -1, Y: 0}).NudgeToSelection()
@@ -120,11 +622,27 @@ func (m GameplayModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 
 		case key.Matches(msg, keymap.Gameplay.Up):
-			m.board = m.board.MoveSelectionBy(Offset{X: 0, Y: -1}).NudgeToSelection()
+			m.board = m.board.MoveSelectionVertically(-1).NudgeToSelection()
 			return m, nil
 
 		case key.Matches(msg, keymap.Gameplay.Down):
-			m.board = m.board.MoveSelectionBy(Offset{X: 0, Y: 1}).NudgeToSelection()
+			m.board = m.board.MoveSelectionVertically(1).NudgeToSelection()
+			return m, nil
+
+		case key.Matches(msg, keymap.Gameplay.UpLeft):
+			m.board = m.board.MoveSelectionDiagonally(-1, -1).NudgeToSelection()
+			return m, nil
+
+		case key.Matches(msg, keymap.Gameplay.UpRight):
+			m.board = m.board.MoveSelectionDiagonally(1, -1).NudgeToSelection()
+			return m, nil
+
+		case key.Matches(msg, keymap.Gameplay.DownLeft):
+			m.board = m.board.MoveSelectionDiagonally(-1, 1).NudgeToSelection()
+			return m, nil
+
+		case key.Matches(msg, keymap.Gameplay.DownRight):
+			m.board = m.board.MoveSelectionDiagonally(1, 1).NudgeToSelection()
 			return m, nil
 
 		case key.Matches(msg, keymap.Gameplay.Select):
@@ -132,63 +650,313 @@ func (m GameplayModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 
-			if m.Game.Cell(m.board.Selection()) != game.CellUnoccupied {
+			if err := m.Game.ValidateMove(m.board.Selection(), m.Game.PlayerToMove()); err != nil {
+				m.moveErr = err
 				return m, nil
 			}
 
-			localPlayer := m.Players[m.CurrentPlayer].(*LocalPlayer)
+			localPlayer := m.Players[m.activeSeat()].(*LocalPlayer)
 			localPlayer.CommitMove(m.board.Selection())
 
 			m.MoveCommitted = true
 			return m, nil
 		}
 
+	case clockTickMsg:
+		if m.timedOut(time.Time(msg)) {
+			return m.loseOnTime()
+		}
+
+		return m, tickClock()
+
 	case PlayerMoveMsg:
-		m.Game.MarkCell(msg.ChosenCell, m.CurrentPlayer)
-		m.MoveCommitted = false
+		// The move was cancelled, and another one is awaited instead
+		if msg.moveID != m.pending.id {
+			return m, nil
+		}
+
+		m.cancelMove()
 
-		m.CurrentPlayer = m.CurrentPlayer.Other()
-		m.board.CurrentPlayer = m.CurrentPlayer
+		if msg.Err != nil {
+			if errors.Is(msg.Err, context.Canceled) {
+				return m, nil
+			}
+
+			log.Printf("gameplay: agent failed to move: %v", msg.Err)
+			return m.rejectMove(msg.Err)
+		}
+
+		// The move might have arrived after the time was up, but before the clock tick
+		if m.timedOut(time.Now()) {
+			return m.loseOnTime()
+		}
+
+		// Agents may be buggy, so their moves are validated instead of crashing the game
+		if err := m.Game.Play(msg.ChosenCell); err != nil {
+			log.Printf("gameplay: rejected move: %v", err)
+			return m.rejectMove(err)
+		}
+
+		m.MoveCommitted = false
+		m.moveErr = nil
+		m.rejectedMoves = 0
+		m.drawDeclined = false
+		m.saved = false
+
+		if m.opening.Step().Kind == game.OpeningPlaceStone {
+			m.opening.StonePlaced()
+			m.openingDone()
+		}
 
 		m.board = m.board.NudgeCameraTo(msg.ChosenCell).SnapSelectionIntoCamera()
 
 		if m.Game.Over() {
-			return GameOverModel{
-				Game:     m.Game,
-				Board:    m.board,
-				Help:     m.help,
-				GameTime: time.Now().Sub(m.gameStartedAt),
-			}, nil
+			return m.gameOver(), nil
+		}
+
+		m.switchClock(time.Now())
+
+		return m, m.AwaitMove()
+
+	case DrawResponseMsg:
+		// The response was cancelled
+		if msg.responseID != m.pendingDraw.id {
+			return m, nil
+		}
+
+		m.cancelDrawResponse()
+
+		if msg.Err != nil {
+			if errors.Is(msg.Err, context.Canceled) {
+				return m, nil
+			}
+
+			log.Printf("gameplay: agent failed to respond to the draw: %v", msg.Err)
+			msg.Accepted = false
+		}
+
+		// The player who offered the draw is still making their move,
+		// so there's nothing to await after a decline
+		m.MoveCommitted = false
+
+		if !msg.Accepted {
+			m.drawOffer = nil
+			m.drawDeclined = true
+			return m, nil
+		}
+
+		m.drawOffer.pendingSeats = m.drawOffer.pendingSeats[1:]
+		if len(m.drawOffer.pendingSeats) > 0 {
+			return m, m.AwaitDrawResponse()
 		}
 
-		return m, m.AwaitMove(m.CurrentPlayer)
+		m.drawOffer = nil
+		if err := m.Game.AgreeDraw(); err != nil {
+			log.Printf("gameplay: rejected draw: %v", err)
+			return m, nil
+		}
+
+		return m.gameOver(), nil
+
+	case OpeningDecisionMsg:
+		// The decision was cancelled, and another one is awaited instead
+		if msg.moveID != m.pending.id {
+			return m, nil
+		}
+
+		m.cancelMove()
+
+		if msg.Err != nil {
+			if errors.Is(msg.Err, context.Canceled) {
+				return m, nil
+			}
+
+			log.Printf("gameplay: agent failed to decide: %v", msg.Err)
+			return m.rejectMove(msg.Err)
+		}
+
+		m.MoveCommitted = false
+
+		if err := m.opening.Decide(msg.Choice); err != nil {
+			log.Printf("gameplay: rejected opening decision: %v", err)
+		}
+
+		m.openingDone()
+
+		m.switchClock(time.Now())
+
+		return m, m.AwaitMove()
 	}
 
 	return m, nil
 }
 
+func hasOpeningChoice(choices []game.OpeningChoice, choice game.OpeningChoice) bool {
+	for _, c := range choices {
+		if c == choice {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m GameplayModel) openingChoiceHelp(choice game.OpeningChoice, me game.PlayerID) string {
+	switch choice {
+	case game.OpeningKeepColor:
+		return fmt.Sprintf("[%s] keep %s", keymap.Gameplay.KeepColor.Help().Key, m.board.Theme.PlayerCells[me])
+	case game.OpeningSwapColor:
+		return fmt.Sprintf("[%s] swap to %s", keymap.Gameplay.SwapColor.Help().Key, m.board.Theme.PlayerCells[me.NextPlayer(2)])
+	case game.OpeningPlaceMore:
+		return fmt.Sprintf("[%s] place two more stones", keymap.Gameplay.PlaceMore.Help().Key)
+	}
+
+	return choice.String()
+}
+
+func (m GameplayModel) openingView() string {
+	var view strings.Builder
+
+	step := m.opening.Step()
+	view.WriteString(fmt.Sprintf("Opening (%v): player %d ", m.opening.Protocol(), step.Seat+1))
+
+	if step.Kind == game.OpeningPlaceStone {
+		view.WriteString("places ")
+		view.WriteString(m.board.Theme.PlayerCells[m.Game.PlayerToMove()])
+		return view.String()
+	}
+
+	me := m.opening.PlayerOf(step.Seat)
+	if !m.IsLocalPlayerTurn() {
+		view.WriteString("chooses colour...")
+		return view.String()
+	}
+
+	view.WriteString("chooses colour: ")
+	for i, choice := range step.Choices {
+		if i > 0 {
+			view.WriteString(", ")
+		}
+
+		view.WriteString(m.openingChoiceHelp(choice, me))
+	}
+
+	return view.String()
+}
+
+func (m GameplayModel) drawOfferView() string {
+	var view strings.Builder
+
+	view.WriteString(m.board.Theme.PlayerCells[m.drawOffer.offeredBy])
+	view.WriteString(" offers a draw, ")
+
+	responder := m.opening.PlayerOf(m.drawOffer.pendingSeats[0])
+	view.WriteString(m.board.Theme.PlayerCells[responder])
+
+	if !m.isLocalDrawResponder() {
+		view.WriteString(" responds...")
+		return view.String()
+	}
+
+	view.WriteString(fmt.Sprintf(" responds: [%s] accept, [%s] decline", keymap.Gameplay.AcceptDraw.Help().Key, keymap.Gameplay.DeclineDraw.Help().Key))
+	return view.String()
+}
+
+// formatClockTime formats the time as minutes and seconds,
+// rounded up, so that zero is shown only when the time is up
+func formatClockTime(d time.Duration) string {
+	seconds := int((d + time.Second - 1) / time.Second)
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+func (m GameplayModel) clockView(now time.Time) string {
+	var view strings.Builder
+	view.WriteString("Time: ")
+
+	running, isRunning := m.clock.Running()
+	for p := 0; p < m.Game.PlayerCount(); p++ {
+		player := game.PlayerID(p)
+		left := m.clock.TimeLeft(player, now)
+
+		var clock string
+		switch {
+		case left.Flagged:
+			clock = "time is up"
+		case left.InByoYomi():
+			clock = fmt.Sprintf("%s (byo-yomi, %d left)", formatClockTime(left.Period), left.Periods)
+		default:
+			clock = formatClockTime(left.Main)
+		}
+
+		clock = fmt.Sprintf("%s %s", m.board.Theme.PlayerCells[player], clock)
+		if isRunning && running == player {
+			clock = runningClockStyle.Render(clock)
+		}
+
+		if p > 0 {
+			view.WriteString(" • ")
+		}
+
+		view.WriteString(clock)
+	}
+
+	return view.String()
+}
+
 func (m GameplayModel) View() string {
-	m.board.SelectionVisible = m.IsLocalPlayerTurn()
+	m.board.SelectionVisible = m.IsLocalPlayerTurn() && !m.awaitingDecision() && m.drawOffer == nil
 
 	var view strings.Builder
 
 	gameModel := GameModel{
-		Game:  m.Game,
-		Board: m.board,
+		Game:      m.Game,
+		Board:     m.board,
+		forbidden: m.forbidden,
 	}
 
 	view.WriteString(gameModel.View())
 	view.WriteByte('\n')
 
-	if m.IsLocalPlayerTurn() {
+	if m.stalled != nil {
+		view.WriteString(errorStyle.Render(m.stalled.Error()))
+	} else if m.drawOffer != nil {
+		view.WriteString(m.drawOfferView())
+	} else if !m.opening.Done() {
+		view.WriteString(m.openingView())
+	} else if m.IsLocalPlayerTurn() {
 		view.WriteString("Current player: ")
-		view.WriteString(m.board.Theme.PlayerCells[m.CurrentPlayer])
+		view.WriteString(m.board.Theme.PlayerCells[m.Game.PlayerToMove()])
 	} else {
 		view.WriteString("Awaiting player ")
-		view.WriteString(m.board.Theme.PlayerCells[m.CurrentPlayer])
+		view.WriteString(m.board.Theme.PlayerCells[m.Game.PlayerToMove()])
 		view.WriteString(" move...")
 	}
 
+	if stonesLeft := m.Game.StonesLeftInTurn(); stonesLeft > 1 {
+		view.WriteString(fmt.Sprintf(" (%d stones left this turn)", stonesLeft))
+	}
+
+	if m.clock != nil {
+		view.WriteByte('\n')
+		view.WriteString(m.clockView(time.Now()))
+	}
+
+	if m.drawDeclined {
+		view.WriteString("\nThe draw offer was declined")
+	}
+
+	if m.moveErr != nil {
+		view.WriteByte('\n')
+		view.WriteString(errorStyle.Render(m.moveErr.Error()))
+	}
+
+	if m.saved {
+		view.WriteString("\nThe game is saved")
+	} else if m.saveErr != nil {
+		view.WriteByte('\n')
+		view.WriteString(errorStyle.Render(m.saveErr.Error()))
+	}
+
 	// view.WriteString(fmt.Sprintf("\nCamera bound: %v | Camera: %v", m.cameraBound, m.Camera))
 	view.WriteString("\n\n")
 
@@ -19,6 +19,22 @@ var (
 		key.WithKeys("down", "j"),
 		key.WithHelp("↓/j", "move down"),
 	)
+	UpLeft = key.NewBinding(
+		key.WithKeys("y"),
+		key.WithHelp("y", "move up-left"),
+	)
+	UpRight = key.NewBinding(
+		key.WithKeys("u"),
+		key.WithHelp("u", "move up-right"),
+	)
+	DownLeft = key.NewBinding(
+		key.WithKeys("b"),
+		key.WithHelp("b", "move down-left"),
+	)
+	DownRight = key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "move down-right"),
+	)
 
 	Select = key.NewBinding(
 		key.WithKeys("enter", " "),
@@ -32,11 +48,49 @@ var (
 		key.WithKeys("q", "esc", "ctrl+c"),
 		key.WithHelp("q", "quit"),
 	)
+	Save = key.NewBinding(
+		key.WithKeys("ctrl+s"),
+		key.WithHelp("ctrl+s", "save game"),
+	)
 	QuitOrSelect = key.NewBinding(
 		key.WithKeys("enter", " ", "q", "esc", "ctrl+c"),
 		key.WithHelp("enter/q", "quit"),
 	)
 
+	KeepColor = key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "keep colour"),
+	)
+	SwapColor = key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "swap colour"),
+	)
+	PlaceMore = key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "place two more stones"),
+	)
+
+	Resign = key.NewBinding(
+		key.WithKeys("R"),
+		key.WithHelp("R", "resign"),
+	)
+	OfferDraw = key.NewBinding(
+		key.WithKeys("d"),
+		key.WithHelp("d", "offer a draw"),
+	)
+	AcceptDraw = key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "accept a draw"),
+	)
+	DeclineDraw = key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "decline a draw"),
+	)
+	Undo = key.NewBinding(
+		key.WithKeys("z"),
+		key.WithHelp("z", "take back your turn"),
+	)
+
 	WatchReplay = key.NewBinding(
 		key.WithKeys("r"),
 		key.WithHelp("r", "watch replay"),
@@ -50,32 +104,63 @@ var (
 		key.WithHelp("r/←", "rewind"),
 	)
 
-	ReplayCameraUp = key.NewBinding(
+	ReplaySelectionUp = key.NewBinding(
 		key.WithKeys("shift+up", "k"),
-		key.WithHelp("k/shift+↑", "forward"),
+		key.WithHelp("k/shift+↑", "move up"),
 	)
-	ReplayCameraDown = key.NewBinding(
+	ReplaySelectionDown = key.NewBinding(
 		key.WithKeys("shift+down", "j"),
-		key.WithHelp("j/shift+↓", "rewind"),
+		key.WithHelp("j/shift+↓", "move down"),
 	)
-	ReplayCameraRight = key.NewBinding(
+	ReplaySelectionRight = key.NewBinding(
 		key.WithKeys("shift+right", "l"),
-		key.WithHelp("l/shift+→", "forward"),
+		key.WithHelp("l/shift+→", "move right"),
 	)
-	ReplayCameraLeft = key.NewBinding(
+	ReplaySelectionLeft = key.NewBinding(
 		key.WithKeys("shift+left", "h"),
-		key.WithHelp("h/shift+←", "rewind"),
+		key.WithHelp("h/shift+←", "move left"),
+	)
+	WhatIf = key.NewBinding(
+		key.WithKeys("enter", " "),
+		key.WithHelp("enter/space", "try a move"),
+	)
+	PrevVariation = key.NewBinding(
+		key.WithKeys("["),
+		key.WithHelp("[", "previous variation"),
+	)
+	NextVariation = key.NewBinding(
+		key.WithKeys("]"),
+		key.WithHelp("]", "next variation"),
+	)
+	MainLine = key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m", "back to main line"),
 	)
 )
 
 var Gameplay = GameplayModel{
-	Left:   Left,
-	Right:  Right,
-	Up:     Up,
-	Down:   Down,
-	Select: Select,
-	Help:   Help,
-	Quit:   Quit,
+	Left:      Left,
+	Right:     Right,
+	Up:        Up,
+	Down:      Down,
+	UpLeft:    UpLeft,
+	UpRight:   UpRight,
+	DownLeft:  DownLeft,
+	DownRight: DownRight,
+	Select:    Select,
+	KeepColor: KeepColor,
+	SwapColor: SwapColor,
+	PlaceMore: PlaceMore,
+
+	Resign:      Resign,
+	OfferDraw:   OfferDraw,
+	AcceptDraw:  AcceptDraw,
+	DeclineDraw: DeclineDraw,
+	Undo:        Undo,
+
+	Help: Help,
+	Save: Save,
+	Quit: Quit,
 }
 
 var GameOver = GameOverModel{
@@ -84,12 +169,18 @@ var GameOver = GameOverModel{
 }
 
 var Replay = ReplayModel{
-	Left:    ReplayCameraLeft,
-	Right:   ReplayCameraRight,
-	Up:      ReplayCameraUp,
-	Down:    ReplayCameraDown,
+	Left:    ReplaySelectionLeft,
+	Right:   ReplaySelectionRight,
+	Up:      ReplaySelectionUp,
+	Down:    ReplaySelectionDown,
 	Forward: Forward,
 	Rewind:  Rewind,
-	Help:    Help,
-	Quit:    Quit,
+
+	WhatIf:        WhatIf,
+	PrevVariation: PrevVariation,
+	NextVariation: NextVariation,
+	MainLine:      MainLine,
+
+	Help: Help,
+	Quit: Quit,
 }
@@ -3,13 +3,28 @@ package keymap
 import "github.com/charmbracelet/bubbles/key"
 
 type GameplayModel struct {
-	Left   key.Binding
-	Right  key.Binding
-	Up     key.Binding
-	Down   key.Binding
-	Select key.Binding
-	Help   key.Binding
-	Quit   key.Binding
+	Left      key.Binding
+	Right     key.Binding
+	Up        key.Binding
+	Down      key.Binding
+	UpLeft    key.Binding
+	UpRight   key.Binding
+	DownLeft  key.Binding
+	DownRight key.Binding
+	Select    key.Binding
+	KeepColor key.Binding
+	SwapColor key.Binding
+	PlaceMore key.Binding
+
+	Resign      key.Binding
+	OfferDraw   key.Binding
+	AcceptDraw  key.Binding
+	DeclineDraw key.Binding
+	Undo        key.Binding
+
+	Help key.Binding
+	Save key.Binding
+	Quit key.Binding
 }
 
 func (k GameplayModel) ShortHelp() []key.Binding {
@@ -19,7 +34,10 @@ func (k GameplayModel) ShortHelp() []key.Binding {
 func (k GameplayModel) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Left, k.Right, k.Up, k.Down, k.Select},
-		{k.Help, k.Quit},
+		{k.UpLeft, k.UpRight, k.DownLeft, k.DownRight},
+		{k.KeepColor, k.SwapColor, k.PlaceMore},
+		{k.Resign, k.OfferDraw, k.AcceptDraw, k.DeclineDraw, k.Undo},
+		{k.Help, k.Save, k.Quit},
 	}
 }
 
@@ -45,8 +63,14 @@ type ReplayModel struct {
 	Down    key.Binding
 	Forward key.Binding
 	Rewind  key.Binding
-	Help    key.Binding
-	Quit    key.Binding
+
+	WhatIf        key.Binding
+	PrevVariation key.Binding
+	NextVariation key.Binding
+	MainLine      key.Binding
+
+	Help key.Binding
+	Quit key.Binding
 }
 
 func (k ReplayModel) ShortHelp() []key.Binding {
@@ -56,6 +80,7 @@ func (k ReplayModel) ShortHelp() []key.Binding {
 func (k ReplayModel) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Left, k.Right, k.Up, k.Down},
+		{k.WhatIf, k.PrevVariation, k.NextVariation, k.MainLine},
 		{k.Forward, k.Rewind, k.Help, k.Quit},
 	}
 }
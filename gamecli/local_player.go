@@ -1,24 +1,136 @@
 package gamecli
 
 import (
+	"context"
+	"sync"
+
 	"github.com/kitsunemikan/six-purrpurrs/game"
 	. "github.com/kitsunemikan/six-purrpurrs/geom"
 )
 
 type LocalPlayer struct {
-	moves chan Offset
+	mu sync.Mutex
+
+	// request is the answer being awaited, if any. An answer committed, while
+	// none is awaited, is kept for the next request, unless it's dropped
+	request   *answerRequest
+	committed *answer
+}
+
+// answer is what the local player commits: a move, an opening decision,
+// or a draw response, depending on what was asked
+type answer struct {
+	move   Offset
+	choice game.OpeningChoice
+	accept bool
+}
+
+type answerRequest struct {
+	ctx     context.Context
+	answers chan answer
 }
 
 func NewLocalPlayer() game.PlayerAgent {
-	return &LocalPlayer{
-		moves: make(chan Offset),
+	return &LocalPlayer{}
+}
+
+// await waits for the answer to be committed, until the context is done
+func (p *LocalPlayer) await(ctx context.Context) (answer, error) {
+	p.mu.Lock()
+	if err := ctx.Err(); err != nil {
+		p.mu.Unlock()
+		return answer{}, err
+	}
+
+	if p.committed != nil {
+		a := *p.committed
+		p.committed = nil
+		p.mu.Unlock()
+		return a, nil
+	}
+
+	request := &answerRequest{ctx, make(chan answer, 1)}
+	p.request = request
+	p.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		p.mu.Lock()
+		if p.request == request {
+			p.request = nil
+		}
+		p.mu.Unlock()
+
+		return answer{}, ctx.Err()
+
+	case a := <-request.answers:
+		return a, nil
 	}
 }
 
-func (p *LocalPlayer) MakeMove(g *game.GameState) Offset {
-	return <-p.moves
+// commit hands the answer to the awaited request. It never blocks, so it's safe
+// to call from the UI. Answers are never handed to cancelled requests, since
+// the gameplay cancels them before the next answer is awaited
+func (p *LocalPlayer) commit(a answer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.request != nil && p.request.ctx.Err() == nil {
+		p.request.answers <- a
+		p.request = nil
+		return
+	}
+
+	p.committed = &a
+}
+
+// dropCommitted forgets the answer committed for a request, which was cancelled
+// before it could take the answer, so it isn't handed to the next request
+func (p *LocalPlayer) dropCommitted() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.committed = nil
+}
+
+func (p *LocalPlayer) MakeMove(g game.View) Offset {
+	move, _ := p.MakeMoveContext(context.Background(), g)
+	return move
+}
+
+func (p *LocalPlayer) MakeMoveContext(ctx context.Context, g game.View) (Offset, error) {
+	a, err := p.await(ctx)
+	return a.move, err
 }
 
 func (p *LocalPlayer) CommitMove(pos Offset) {
-	p.moves <- pos
+	p.commit(answer{move: pos})
+}
+
+func (p *LocalPlayer) DecideOpening(g game.View, me game.PlayerID, choices []game.OpeningChoice) game.OpeningChoice {
+	choice, _ := p.DecideOpeningContext(context.Background(), g, me, choices)
+	return choice
+}
+
+func (p *LocalPlayer) DecideOpeningContext(ctx context.Context, g game.View, me game.PlayerID, choices []game.OpeningChoice) (game.OpeningChoice, error) {
+	a, err := p.await(ctx)
+	return a.choice, err
+}
+
+func (p *LocalPlayer) CommitDecision(choice game.OpeningChoice) {
+	p.commit(answer{choice: choice})
+}
+
+func (p *LocalPlayer) RespondToDraw(g game.View, me, offeredBy game.PlayerID) bool {
+	accept, _ := p.RespondToDrawContext(context.Background(), g, me, offeredBy)
+	return accept
+}
+
+func (p *LocalPlayer) RespondToDrawContext(ctx context.Context, g game.View, me, offeredBy game.PlayerID) (bool, error) {
+	a, err := p.await(ctx)
+	return a.accept, err
+}
+
+func (p *LocalPlayer) CommitDrawResponse(accept bool) {
+	p.commit(answer{accept: accept})
 }
@@ -21,13 +21,33 @@ type ReplayModelOptions struct {
 	Board  BoardModel
 	Help   help.Model
 	Parent tea.Model
+
+	// Players follow the replay, if they're game followers, optional
+	Players []game.PlayerAgent
 }
 
+// ReplayModel steps through the moves of a finished game. Moves made
+// during the replay start "what if" variations, which are discarded on quit
 type ReplayModel struct {
-	game     *game.GameState
-	board    BoardModel
-	moves    []game.PlayerMove
-	nextMove int
+	game  *game.GameState
+	tree  *game.GameTree
+	board BoardModel
+
+	forbidden *forbiddenCells
+
+	// stopFollowing stops sending the moves made while stepping
+	// through the game to the players following it
+	stopFollowing func()
+
+	// end is the final position of the game, which is restored on quit
+	end *game.MoveNode
+
+	// result is restored after the replay, since resignations and draw
+	// agreements are taken back by rewinding
+	result game.GameResult
+
+	// moveErr explains why the last tried move was rejected
+	moveErr error
 
 	help     help.Model
 	progress progress.Model
@@ -42,12 +62,21 @@ func NewReplayModel(config ReplayModelOptions) ReplayModel {
 		progress.WithoutPercentage(),
 	)
 
-	moveHistory := config.Game.MoveHistoryCopy()
+	tree := game.NewGameTree(config.Game)
+
+	board := config.Board
+	board.SelectionVisible = true
+
 	return ReplayModel{
-		game:     config.Game,
-		board:    config.Board,
-		moves:    moveHistory,
-		nextMove: len(moveHistory),
+		game:   config.Game,
+		tree:   tree,
+		board:  board,
+		end:    tree.Current(),
+		result: config.Game.Result(),
+
+		forbidden: newForbiddenCells(),
+
+		stopFollowing: game.StartFollowing(config.Game, config.Players),
 
 		help:     config.Help,
 		progress: progress,
@@ -61,17 +90,33 @@ func (m ReplayModel) Init() tea.Cmd {
 	}
 }
 
+// followCurrentMove puts the selection onto the latest move
+func (m *ReplayModel) followCurrentMove() tea.Cmd {
+	current := m.tree.Current()
+	if current.IsRoot() {
+		m.board = m.board.CenterOnBoard()
+	} else {
+		m.board = m.board.MoveSelectionTo(current.Move().Cell).NudgeToSelection()
+	}
+
+	return m.progress.SetPercent(float64(current.Depth()) / float64(m.tree.LineLength()))
+}
+
 func (m ReplayModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch {
 		case key.Matches(msg, keymap.Replay.Quit):
+			m.tree.GoTo(m.end)
 
-			for m.nextMove < len(m.moves) {
-				move := m.moves[m.nextMove]
-				m.game.MarkCell(move.Cell, move.Player)
-				m.nextMove++
+			if !m.game.Over() && m.result.Over() {
+				if err := m.game.Adjudicate(m.result); err != nil {
+					panic(fmt.Sprintf("replay: restore result: %v", err))
+				}
 			}
+
+			// The followers see the end of the game restored, before they're stopped
+			m.stopFollowing()
 			return m.parent, nil
 
 		case key.Matches(msg, keymap.Replay.Help):
@@ -79,50 +124,67 @@ func (m ReplayModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 
 		case key.Matches(msg, keymap.Replay.Left):
-			m.board = m.board.MoveCameraBy(Offset{X: -1, Y: 0})
+			m.board = m.board.MoveSelectionBy(Offset{X: -1, Y: 0}).NudgeToSelection()
 			return m, nil
 
 		case key.Matches(msg, keymap.Replay.Right):
-			m.board = m.board.MoveCameraBy(Offset{X: 1, Y: 0})
+			m.board = m.board.MoveSelectionBy(Offset{X: 1, Y: 0}).NudgeToSelection()
 			return m, nil
 
 		case key.Matches(msg, keymap.Replay.Up):
-			m.board = m.board.MoveCameraBy(Offset{X: 0, Y: -1})
+			m.board = m.board.MoveSelectionVertically(-1).NudgeToSelection()
 			return m, nil
 
 		case key.Matches(msg, keymap.Replay.Down):
-			m.board = m.board.MoveCameraBy(Offset{X: 0, Y: 1})
+			m.board = m.board.MoveSelectionVertically(1).NudgeToSelection()
 			return m, nil
 
-		case key.Matches(msg, keymap.Replay.Forward):
-			if m.nextMove == len(m.moves) {
+		case key.Matches(msg, keymap.Replay.WhatIf):
+			m.moveErr = m.tree.Play(m.board.Selection())
+			if m.moveErr != nil {
 				return m, nil
 			}
 
-			move := m.moves[m.nextMove]
-			m.game.MarkCell(move.Cell, move.Player)
-			m.nextMove++
+			cmd := m.followCurrentMove()
+			return m, cmd
 
-			m.board = m.board.MoveSelectionTo(move.Cell).NudgeToSelection()
+		case key.Matches(msg, keymap.Replay.PrevVariation, keymap.Replay.NextVariation):
+			delta := 1
+			if key.Matches(msg, keymap.Replay.PrevVariation) {
+				delta = -1
+			}
 
-			cmd := m.progress.SetPercent(float64(m.nextMove) / float64(len(m.moves)))
+			if !m.tree.SwitchVariation(delta) {
+				return m, nil
+			}
+
+			m.moveErr = nil
+			cmd := m.followCurrentMove()
 			return m, cmd
 
-		case key.Matches(msg, keymap.Replay.Rewind):
-			if m.nextMove == 0 {
+		case key.Matches(msg, keymap.Replay.MainLine):
+			m.tree.ReturnToMainLine()
+
+			m.moveErr = nil
+			cmd := m.followCurrentMove()
+			return m, cmd
+
+		case key.Matches(msg, keymap.Replay.Forward):
+			if !m.tree.Redo() {
 				return m, nil
 			}
 
-			m.game.UndoLastMove()
-			m.nextMove--
+			m.moveErr = nil
+			cmd := m.followCurrentMove()
+			return m, cmd
 
-			if m.nextMove == 0 {
-				m.board = m.board.CenterOnBoard()
-			} else {
-				m.board = m.board.MoveSelectionTo(m.moves[m.nextMove-1].Cell).NudgeToSelection()
+		case key.Matches(msg, keymap.Replay.Rewind):
+			if !m.tree.Undo() {
+				return m, nil
 			}
 
-			cmd := m.progress.SetPercent(float64(m.nextMove) / float64(len(m.moves)))
+			m.moveErr = nil
+			cmd := m.followCurrentMove()
 			return m, cmd
 		}
 
@@ -143,13 +205,29 @@ func (m ReplayModel) View() string {
 	var view strings.Builder
 
 	gameModel := GameModel{
-		Game:  m.game,
-		Board: m.board,
+		Game:      m.game,
+		Board:     m.board,
+		forbidden: m.forbidden,
 	}
 
 	view.WriteString(gameModel.View())
 	view.WriteString("\n")
-	view.WriteString(fmt.Sprintf("Move %d/%d\n", m.nextMove, len(m.moves)))
+	current := m.tree.Current()
+	view.WriteString(fmt.Sprintf("Move %d/%d", current.Depth(), m.tree.LineLength()))
+
+	if variations := m.tree.Variations(); len(variations) > 1 {
+		view.WriteString(fmt.Sprintf(", variation %d/%d", m.tree.VariationIndex()+1, len(variations)))
+	}
+
+	if !current.IsMainLine() {
+		view.WriteString(" (what if)")
+	}
+
+	if m.moveErr != nil {
+		view.WriteString(fmt.Sprintf("\nInvalid move: %v", m.moveErr))
+	}
+
+	view.WriteString("\n")
 	view.WriteString(m.progress.View())
 	view.WriteString("\n\n")
 	view.WriteString(m.help.View(keymap.Replay))
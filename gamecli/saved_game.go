@@ -0,0 +1,148 @@
+package gamecli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/kitsunemikan/six-purrpurrs/game"
+	"github.com/kitsunemikan/six-purrpurrs/geom"
+	"github.com/kitsunemikan/six-purrpurrs/notation"
+)
+
+// SavedGame is a game written to a file, so that it can be resumed later
+type SavedGame struct {
+	Options game.GameOptions `json:"options"`
+
+	// PlayerTypes name the logic of each player in seat order, see the -players flag
+	PlayerTypes []string `json:"playerTypes"`
+	Avatars     []string `json:"avatars"`
+
+	Opening          game.OpeningProtocol `json:"opening"`
+	OpeningDecisions []game.OpeningChoice `json:"openingDecisions,omitempty"`
+
+	// SetupStones is the number of first moves that set up the starting position,
+	// see the -position flag. They aren't checked against the move rules
+	SetupStones int `json:"setupStones,omitempty"`
+
+	Moves  []game.PlayerMove `json:"moves"`
+	Result game.GameResult   `json:"result"`
+}
+
+// NewSavedGame records the game played with the options
+func NewSavedGame(options game.GameOptions, g *game.GameState, opening *game.Opening, playerTypes, avatars []string) SavedGame {
+	return SavedGame{
+		Options:          options,
+		PlayerTypes:      playerTypes,
+		Avatars:          avatars,
+		Opening:          opening.Protocol(),
+		OpeningDecisions: opening.Decisions(),
+		Moves:            g.MoveHistoryCopy(),
+		Result:           g.Result(),
+	}
+}
+
+func WriteSavedGame(path string, saved SavedGame) error {
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return fmt.Errorf("write saved game: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("write saved game: %w", err)
+	}
+
+	return nil
+}
+
+func ReadSavedGame(path string) (SavedGame, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return SavedGame{}, fmt.Errorf("read saved game: %w", err)
+	}
+
+	var saved SavedGame
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return SavedGame{}, fmt.Errorf("read saved game %s: %w", path, err)
+	}
+
+	return saved, nil
+}
+
+// Restore rebuilds the game by replaying the moves. The opening decisions
+// are made as soon as the opening expects them
+func (s SavedGame) Restore() (*game.GameState, *game.Opening, error) {
+	if err := s.Options.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("restore game: %w", err)
+	}
+
+	g := game.NewGame(s.Options)
+	opening := game.NewOpening(s.Opening)
+
+	decisions := s.OpeningDecisions
+	decide := func() error {
+		for len(decisions) > 0 && opening.Step().Kind == game.OpeningDecide {
+			if err := opening.Decide(decisions[0]); err != nil {
+				return fmt.Errorf("restore game: %w", err)
+			}
+
+			decisions = decisions[1:]
+		}
+
+		return nil
+	}
+
+	if s.SetupStones < 0 || s.SetupStones > len(s.Moves) {
+		return nil, nil, fmt.Errorf("restore game: got %d setup stones for %d moves", s.SetupStones, len(s.Moves))
+	}
+
+	if s.SetupStones > 0 {
+		position := notation.Position{
+			Stones: make(map[geom.Offset]game.PlayerID),
+			ToMove: g.TurnPolicy().PlayerAt(s.SetupStones+1, s.Options.PlayerCount),
+		}
+
+		for _, move := range s.Moves[:s.SetupStones] {
+			position.Stones[move.Cell] = move.Player
+		}
+
+		if len(position.Stones) != s.SetupStones {
+			return nil, nil, fmt.Errorf("restore game: setup stones: %w", game.ErrCellOccupied)
+		}
+
+		if err := position.Place(g); err != nil {
+			return nil, nil, fmt.Errorf("restore game: %w", err)
+		}
+	}
+
+	for i, move := range s.Moves[s.SetupStones:] {
+		if err := decide(); err != nil {
+			return nil, nil, err
+		}
+
+		if err := g.TryMove(move.Cell, move.Player); err != nil {
+			return nil, nil, fmt.Errorf("restore game: move %d: %w", s.SetupStones+i+1, err)
+		}
+
+		if opening.Step().Kind == game.OpeningPlaceStone {
+			opening.StonePlaced()
+		}
+	}
+
+	if err := decide(); err != nil {
+		return nil, nil, err
+	}
+
+	if len(decisions) > 0 {
+		return nil, nil, fmt.Errorf("restore game: %d opening decisions were never expected", len(decisions))
+	}
+
+	// Resignations and agreements don't follow from the moves
+	if !g.Over() && s.Result.Over() {
+		if err := g.Adjudicate(s.Result); err != nil {
+			return nil, nil, fmt.Errorf("restore game: %w", err)
+		}
+	}
+
+	return g, opening, nil
+}
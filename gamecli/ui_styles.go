@@ -8,13 +8,18 @@ import (
 var DefaultBoardTheme = BoardTheme{
 	InvalidCell:    " ",
 	UnoccupiedCell: ".",
-	PlayerCells:    []string{"X", "O"},
+	BlockedCell:    "#",
+	PlayerCells:    []string{"X", "O", "A", "V"},
 
 	PlayerCellStyles: []lipgloss.Style{
 		// SlateBlue1
 		lipgloss.NewStyle().Foreground(lipgloss.Color("99")),
 		// Orange3
 		lipgloss.NewStyle().Foreground(lipgloss.Color("172")),
+		// DeepSkyBlue2
+		lipgloss.NewStyle().Foreground(lipgloss.Color("38")),
+		// IndianRed1
+		lipgloss.NewStyle().Foreground(lipgloss.Color("203")),
 	},
 
 	CandidateCellStyle: lipgloss.NewStyle().
@@ -27,6 +32,15 @@ var DefaultBoardTheme = BoardTheme{
 	LastEnemyCellStyle: lipgloss.NewStyle().
 		Background(lipgloss.Color("88")),
 
+	ForbiddenCellStyle: lipgloss.NewStyle().
+		Foreground(lipgloss.Color("160")),
+
+	BlockedCellStyle: lipgloss.NewStyle().
+		Foreground(lipgloss.Color("137")),
+
+	WrappedCellStyle: lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240")),
+
 	SelectionInactiveStyle: lipgloss.NewStyle().
 		Bold(true).
 		Foreground(lipgloss.Color("8")),
@@ -36,6 +50,10 @@ var helpKeyStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#8a8a8a"))
 
 var helpDescStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#626262"))
 
+var errorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("160"))
+
+var runningClockStyle = lipgloss.NewStyle().Bold(true)
+
 var helpSepStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#4A4A4A"))
 
 var HelpStyle = help.Styles{
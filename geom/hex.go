@@ -0,0 +1,68 @@
+package geom
+
+// Hex cells are addressed with axial coordinates, where X is the column
+// and Y is the row of a pointy-top hex grid. Such a grid is displayed with
+// staggered rows, where odd rows are shifted by half a cell to the right.
+
+// HexNeighbours are the offsets of the six cells adjacent to a hex cell
+var HexNeighbours = []Offset{
+	{X: 1, Y: 0},
+	{X: 1, Y: -1},
+	{X: 0, Y: -1},
+	{X: -1, Y: 0},
+	{X: -1, Y: 1},
+	{X: 0, Y: 1},
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+
+	return x
+}
+
+// HexDistance returns the number of steps between two hex cells
+func (a Offset) HexDistance(b Offset) int {
+	d := a.Sub(b)
+	return (abs(d.X) + abs(d.Y) + abs(d.X+d.Y)) / 2
+}
+
+func (a Offset) IsInsideHexagon(radius int) bool {
+	return a.HexDistance(Offset{}) <= radius
+}
+
+// HexToStaggered converts axial hex coordinates into the staggered display coordinates
+func HexToStaggered(hex Offset) Offset {
+	// Arithmetic shift rounds towards negative infinity for odd negative rows
+	return Offset{X: hex.X + hex.Y>>1, Y: hex.Y}
+}
+
+// StaggeredToHex converts staggered display coordinates into axial hex coordinates
+func StaggeredToHex(display Offset) Offset {
+	return Offset{X: display.X - display.Y>>1, Y: display.Y}
+}
+
+// HexRectToStaggered returns the smallest display rectangle containing
+// all the hex cells of the rectangle in axial coordinates
+func HexRectToStaggered(r Rect) Rect {
+	if r.W <= 0 || r.H <= 0 {
+		return r
+	}
+
+	topLeft := HexToStaggered(r.TopLeft())
+	bound := Rect{X: topLeft.X, Y: topLeft.Y, W: 1, H: 1}
+
+	// Rows are sheared, so the extreme columns are among the corners
+	corners := []Offset{
+		{X: r.X + r.W - 1, Y: r.Y},
+		{X: r.X, Y: r.Y + r.H - 1},
+		{X: r.X + r.W - 1, Y: r.Y + r.H - 1},
+	}
+
+	for _, corner := range corners {
+		bound = bound.GrowToContainOffset(HexToStaggered(corner))
+	}
+
+	return bound
+}
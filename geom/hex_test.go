@@ -0,0 +1,76 @@
+package geom_test
+
+import (
+	"testing"
+
+	"github.com/kitsunemikan/six-purrpurrs/geom"
+)
+
+func TestHexStaggeredRoundTrip(t *testing.T) {
+	for y := -5; y <= 5; y++ {
+		for x := -5; x <= 5; x++ {
+			hex := geom.Offset{X: x, Y: y}
+
+			got := geom.StaggeredToHex(geom.HexToStaggered(hex))
+			if got != hex {
+				t.Errorf("round trip of %v: got %v", hex, got)
+			}
+		}
+	}
+}
+
+func TestHexToStaggered(t *testing.T) {
+	cases := []struct {
+		Desc string
+		Hex  geom.Offset
+		Want geom.Offset
+	}{
+		{"Origin", geom.Offset{X: 0, Y: 0}, geom.Offset{X: 0, Y: 0}},
+		{"Up-left neighbour", geom.Offset{X: 0, Y: -1}, geom.Offset{X: -1, Y: -1}},
+		{"Up-right neighbour", geom.Offset{X: 1, Y: -1}, geom.Offset{X: 0, Y: -1}},
+		{"Down-left neighbour", geom.Offset{X: -1, Y: 1}, geom.Offset{X: -1, Y: 1}},
+		{"Down-right neighbour", geom.Offset{X: 0, Y: 1}, geom.Offset{X: 0, Y: 1}},
+		{"Two rows down", geom.Offset{X: 0, Y: 2}, geom.Offset{X: 1, Y: 2}},
+		{"Two rows up", geom.Offset{X: 0, Y: -2}, geom.Offset{X: -1, Y: -2}},
+	}
+
+	for _, test := range cases {
+		t.Run(test.Desc, func(t *testing.T) {
+			got := geom.HexToStaggered(test.Hex)
+			if got != test.Want {
+				t.Errorf("got %v, want %v", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestHexDistance(t *testing.T) {
+	for _, neighbour := range geom.HexNeighbours {
+		if d := neighbour.HexDistance(geom.Offset{}); d != 1 {
+			t.Errorf("neighbour %v: got distance %d, want 1", neighbour, d)
+		}
+	}
+
+	if d := (geom.Offset{X: 2, Y: -1}).HexDistance(geom.Offset{X: -1, Y: 1}); d != 3 {
+		t.Errorf("got distance %d, want 3", d)
+	}
+}
+
+func TestHexRectToStaggered(t *testing.T) {
+	r := geom.Rect{X: -1, Y: -1, W: 3, H: 3}
+
+	got := geom.HexRectToStaggered(r)
+	for y := r.Y; y < r.Y+r.H; y++ {
+		for x := r.X; x < r.X+r.W; x++ {
+			staggered := geom.HexToStaggered(geom.Offset{X: x, Y: y})
+			if !staggered.IsInsideRect(got) {
+				t.Errorf("%v (displayed at %v) is outside of %v", geom.Offset{X: x, Y: y}, staggered, got)
+			}
+		}
+	}
+
+	want := geom.Rect{X: -2, Y: -1, W: 4, H: 3}
+	if !got.IsEqual(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -3,7 +3,8 @@ package geom
 import "fmt"
 
 type Offset struct {
-	X, Y int
+	X int `json:"x"`
+	Y int `json:"y"`
 }
 
 func (a Offset) Add(b Offset) Offset {
@@ -67,6 +68,25 @@ func (a Offset) SnapIntoRect(r Rect) Offset {
 	return a
 }
 
+// WrapIntoRect maps the offset into the rectangle as if its opposite
+// edges were glued together, i.e., the rectangle is a torus
+func (a Offset) WrapIntoRect(r Rect) Offset {
+	return Offset{
+		X: r.X + mod(a.X-r.X, r.W),
+		Y: r.Y + mod(a.Y-r.Y, r.H),
+	}
+}
+
+// mod returns a non-negative remainder
+func mod(a, m int) int {
+	a %= m
+	if a < 0 {
+		a += m
+	}
+
+	return a
+}
+
 func (a Offset) String() string {
 	return fmt.Sprintf("(%v;%v)", a.X, a.Y)
 }
@@ -79,3 +79,47 @@ func TestOffsetSnapIntoRect(t *testing.T) {
 		})
 	}
 }
+
+func TestOffsetWrapIntoRect(t *testing.T) {
+	cases := []struct {
+		Desc  string
+		Point geom.Offset
+		Rect  geom.Rect
+		Want  geom.Offset
+	}{
+		{
+			"Point inside rect doesn't change",
+			geom.Offset{X: 1, Y: 2},
+			geom.Rect{X: -1, Y: 0, W: 3, H: 3},
+			geom.Offset{X: 1, Y: 2},
+		},
+		{
+			"Point past the right edge",
+			geom.Offset{X: 2, Y: 1},
+			geom.Rect{X: -1, Y: 0, W: 3, H: 3},
+			geom.Offset{X: -1, Y: 1},
+		},
+		{
+			"Point past the left edge",
+			geom.Offset{X: -2, Y: 1},
+			geom.Rect{X: -1, Y: 0, W: 3, H: 3},
+			geom.Offset{X: 1, Y: 1},
+		},
+		{
+			"Point several boards away",
+			geom.Offset{X: 8, Y: -7},
+			geom.Rect{X: -1, Y: 0, W: 3, H: 3},
+			geom.Offset{X: -1, Y: 2},
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.Desc, func(t *testing.T) {
+			got := test.Point.WrapIntoRect(test.Rect)
+
+			if !got.IsEqual(test.Want) {
+				t.Errorf("got %v, want %v, when wrapping %v into %v", got, test.Want, test.Point, test.Rect)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package geom
+
+import "fmt"
+
+// Symmetry is one of the 8 symmetries of a square grid around the origin:
+// a rotation by a multiple of 90 degrees or a reflection. Since Y grows
+// downwards, rotations are clockwise on the screen
+type Symmetry int
+
+const (
+	Identity Symmetry = iota
+	Rotate90
+	Rotate180
+	Rotate270
+
+	// ReflectX mirrors across the X axis
+	ReflectX
+
+	// ReflectY mirrors across the Y axis
+	ReflectY
+
+	// ReflectDiagonal mirrors across the X=Y line, i.e., transposes
+	ReflectDiagonal
+
+	// ReflectAntiDiagonal mirrors across the X=-Y line
+	ReflectAntiDiagonal
+)
+
+// Symmetries are all the symmetries of a square grid
+var Symmetries = []Symmetry{
+	Identity,
+	Rotate90,
+	Rotate180,
+	Rotate270,
+	ReflectX,
+	ReflectY,
+	ReflectDiagonal,
+	ReflectAntiDiagonal,
+}
+
+func (s Symmetry) String() string {
+	switch s {
+	case Identity:
+		return "identity"
+	case Rotate90:
+		return "rotate 90"
+	case Rotate180:
+		return "rotate 180"
+	case Rotate270:
+		return "rotate 270"
+	case ReflectX:
+		return "reflect x"
+	case ReflectY:
+		return "reflect y"
+	case ReflectDiagonal:
+		return "reflect diagonal"
+	case ReflectAntiDiagonal:
+		return "reflect anti-diagonal"
+	}
+
+	return fmt.Sprintf("UnknownSymmetry%d", int(s))
+}
+
+// Apply transforms the offset around the origin
+func (s Symmetry) Apply(a Offset) Offset {
+	switch s {
+	case Identity:
+		return a
+	case Rotate90:
+		return Offset{-a.Y, a.X}
+	case Rotate180:
+		return Offset{-a.X, -a.Y}
+	case Rotate270:
+		return Offset{a.Y, -a.X}
+	case ReflectX:
+		return Offset{a.X, -a.Y}
+	case ReflectY:
+		return Offset{-a.X, a.Y}
+	case ReflectDiagonal:
+		return Offset{a.Y, a.X}
+	case ReflectAntiDiagonal:
+		return Offset{-a.Y, -a.X}
+	}
+
+	panic(fmt.Sprintf("symmetry: apply: unknown symmetry %v", s))
+}
+
+// Inverse returns the symmetry that undoes this one
+func (s Symmetry) Inverse() Symmetry {
+	switch s {
+	case Rotate90:
+		return Rotate270
+	case Rotate270:
+		return Rotate90
+	}
+
+	// The rest are their own inverses
+	return s
+}
+
+// Transform is a symmetry followed by a translation
+type Transform struct {
+	Symmetry Symmetry
+	Shift    Offset
+}
+
+// Apply transforms the offset
+func (t Transform) Apply(a Offset) Offset {
+	return t.Symmetry.Apply(a).Add(t.Shift)
+}
+
+// Inverse returns the transform that undoes this one
+func (t Transform) Inverse() Transform {
+	inverse := t.Symmetry.Inverse()
+	return Transform{
+		Symmetry: inverse,
+		Shift:    inverse.Apply(t.Shift).ScaleUp(-1),
+	}
+}
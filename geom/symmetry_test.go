@@ -0,0 +1,72 @@
+package geom_test
+
+import (
+	"testing"
+
+	"github.com/kitsunemikan/six-purrpurrs/geom"
+)
+
+func TestSymmetryApply(t *testing.T) {
+	cases := []struct {
+		Symmetry geom.Symmetry
+		Want     geom.Offset
+	}{
+		{geom.Identity, geom.Offset{X: 2, Y: 1}},
+		{geom.Rotate90, geom.Offset{X: -1, Y: 2}},
+		{geom.Rotate180, geom.Offset{X: -2, Y: -1}},
+		{geom.Rotate270, geom.Offset{X: 1, Y: -2}},
+		{geom.ReflectX, geom.Offset{X: 2, Y: -1}},
+		{geom.ReflectY, geom.Offset{X: -2, Y: 1}},
+		{geom.ReflectDiagonal, geom.Offset{X: 1, Y: 2}},
+		{geom.ReflectAntiDiagonal, geom.Offset{X: -1, Y: -2}},
+	}
+
+	for _, test := range cases {
+		t.Run(test.Symmetry.String(), func(t *testing.T) {
+			got := test.Symmetry.Apply(geom.Offset{X: 2, Y: 1})
+			if got != test.Want {
+				t.Errorf("got %v, want %v", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestSymmetryGroup(t *testing.T) {
+	a := geom.Offset{X: 3, Y: -5}
+
+	// The symmetries are distinct and each one is undone by its inverse
+	seen := make(map[geom.Offset]geom.Symmetry)
+	for _, s := range geom.Symmetries {
+		got := s.Apply(a)
+		if other, exists := seen[got]; exists {
+			t.Errorf("%v and %v both map %v to %v", s, other, a, got)
+		}
+
+		seen[got] = s
+
+		if back := s.Inverse().Apply(got); back != a {
+			t.Errorf("%v: inverse maps %v back to %v, want %v", s, got, back, a)
+		}
+	}
+
+	// The symmetries are closed under composition
+	for _, s := range geom.Symmetries {
+		for _, r := range geom.Symmetries {
+			if _, exists := seen[r.Apply(s.Apply(a))]; !exists {
+				t.Errorf("%v after %v isn't a symmetry", r, s)
+			}
+		}
+	}
+}
+
+func TestTransformInverse(t *testing.T) {
+	for _, s := range geom.Symmetries {
+		transform := geom.Transform{Symmetry: s, Shift: geom.Offset{X: 4, Y: -7}}
+
+		for _, a := range []geom.Offset{{X: 0, Y: 0}, {X: 1, Y: 2}, {X: -3, Y: 5}} {
+			if back := transform.Inverse().Apply(transform.Apply(a)); back != a {
+				t.Errorf("%v: inverse maps %v back to %v", transform, a, back)
+			}
+		}
+	}
+}
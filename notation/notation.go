@@ -0,0 +1,116 @@
+// Package notation reads and writes games in a PGN-like text notation:
+//
+//	[Players "Alice, Bob"]
+//	[Date "2022.05.14"]
+//	[Variant "connect6"]
+//	[Strike "6"]
+//	[Border "7"]
+//	[Result "P1: win by strike"]
+//
+//	1. 0,0 2. 1,-1 1,1 3. -2,0 2,0
+//
+// The header is a list of tags, one per line. The players are separated by commas,
+// so the commas in their names are escaped with a backslash. The moves are grouped
+// into numbered turns, and each stone is written as x,y board coordinates, which
+// may be negative. The player of each stone follows from the variant, i.e., the turn policy
+package notation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kitsunemikan/six-purrpurrs/game"
+)
+
+// Variants are the turn policies known to the notation by name, which are
+// also the names the command line accepts
+var Variants = map[string]game.TurnPolicy{
+	"single":   game.SingleStoneTurns,
+	"connect6": game.Connect6Turns,
+}
+
+// Defaults for the tags missing from the header
+const (
+	DefaultVariant      = "single"
+	DefaultStrikeLength = 6
+	DefaultBorder       = 7
+)
+
+// Game is a game written in the notation
+type Game struct {
+	// Players are the names of the players in turn order
+	Players []string
+
+	// Date is free-form, though PGN-like YYYY.MM.DD is recommended
+	Date string
+
+	Variant      string
+	StrikeLength int
+	Border       int
+
+	Result game.GameResult
+	Moves  []game.PlayerMove
+}
+
+// Options returns the options of the game described by the header
+func (g *Game) Options() (game.GameOptions, error) {
+	turns, known := Variants[g.Variant]
+	if !known {
+		return game.GameOptions{}, fmt.Errorf("unknown variant %q", g.Variant)
+	}
+
+	if len(g.Players) < 2 {
+		return game.GameOptions{}, fmt.Errorf("at least 2 players are required, got %d", len(g.Players))
+	}
+
+	if g.StrikeLength < 1 {
+		return game.GameOptions{}, fmt.Errorf("non-positive strike length %d", g.StrikeLength)
+	}
+
+	return game.GameOptions{
+		Border:      g.Border,
+		PlayerCount: len(g.Players),
+		Turns:       turns,
+		Victory:     &game.EightDirStrikeVictoryChecker{VictoryLength: g.StrikeLength},
+	}, nil
+}
+
+// ongoingResult marks unfinished games, like in PGN
+const ongoingResult = "*"
+
+func formatResult(result game.GameResult) string {
+	if !result.Over() {
+		return ongoingResult
+	}
+
+	return result.String()
+}
+
+func parseResult(text string) (game.GameResult, error) {
+	if text == ongoingResult {
+		return game.GameResult{}, nil
+	}
+
+	var result game.GameResult
+
+	outcome := text
+	if winner, won, isWin := strings.Cut(text, ": "); isWin {
+		var number int
+		if _, err := fmt.Sscanf(winner, "P%d", &number); err != nil || number < 1 {
+			return game.GameResult{}, fmt.Errorf("invalid winner %q", winner)
+		}
+
+		result.Winner = game.PlayerID(number - 1)
+		outcome = won
+	}
+
+	if err := result.Outcome.UnmarshalText([]byte(outcome)); err != nil {
+		return game.GameResult{}, err
+	}
+
+	if result.Outcome.IsWin() != (outcome != text) {
+		return game.GameResult{}, fmt.Errorf("winner doesn't match the outcome %q", outcome)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,161 @@
+package notation_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"github.com/kitsunemikan/six-purrpurrs/game"
+	"github.com/kitsunemikan/six-purrpurrs/geom"
+	"github.com/kitsunemikan/six-purrpurrs/notation"
+)
+
+func TestWriteParse(t *testing.T) {
+	g := &notation.Game{
+		Players:      []string{"Alice", `Bob "the Builder"`},
+		Date:         "2022.05.14",
+		Variant:      "connect6",
+		StrikeLength: 3,
+		Border:       3,
+		Result:       game.GameResult{Outcome: game.OutcomeStrike, Winner: game.P2},
+		Moves: []game.PlayerMove{
+			{Cell: geom.Offset{X: 0, Y: 0}, Player: game.P1},
+			{Cell: geom.Offset{X: -1, Y: -1}, Player: game.P2},
+			{Cell: geom.Offset{X: -1, Y: 0}, Player: game.P2},
+			{Cell: geom.Offset{X: 1, Y: 0}, Player: game.P1},
+			{Cell: geom.Offset{X: 2, Y: 2}, Player: game.P1},
+			{Cell: geom.Offset{X: -1, Y: 1}, Player: game.P2},
+		},
+	}
+
+	var text strings.Builder
+	td.CmpNoError(t, notation.Write(&text, g))
+
+	td.Cmp(t, text.String(), `[Players "Alice, Bob \"the Builder\""]
+[Date "2022.05.14"]
+[Variant "connect6"]
+[Strike "3"]
+[Border "3"]
+[Result "P2: win by strike"]
+
+1. 0,0 2. -1,-1 -1,0 3. 1,0 2,2 4. -1,1
+`)
+
+	parsed, err := notation.Parse(strings.NewReader(text.String()))
+	td.CmpNoError(t, err)
+	td.Cmp(t, parsed, g)
+}
+
+func TestWriteParsePlayerNames(t *testing.T) {
+	g := &notation.Game{
+		Players:      []string{"Smith, John", `C:\Users\bob`, "Eve"},
+		Variant:      notation.DefaultVariant,
+		StrikeLength: 3,
+		Border:       3,
+	}
+
+	var text strings.Builder
+	td.CmpNoError(t, notation.Write(&text, g))
+	td.Cmp(t, text.String(), td.HasPrefix(`[Players "Smith\\, John, C:\\\\Users\\\\bob, Eve"]`))
+
+	parsed, err := notation.Parse(strings.NewReader(text.String()))
+	td.CmpNoError(t, err)
+	td.Cmp(t, parsed.Players, g.Players)
+}
+
+func TestWriteWrapsLines(t *testing.T) {
+	g := &notation.Game{
+		Players:      []string{"?", "?"},
+		Variant:      "single",
+		StrikeLength: 6,
+		Border:       20,
+	}
+
+	for i := 0; i < 20; i++ {
+		g.Moves = append(g.Moves, game.PlayerMove{Cell: geom.Offset{X: -10 + i, Y: -10 + i%2}, Player: game.PlayerID(i % 2)})
+	}
+
+	var text strings.Builder
+	td.CmpNoError(t, notation.Write(&text, g))
+
+	for _, line := range strings.Split(text.String(), "\n") {
+		td.Cmp(t, len(line), td.Lte(80), "line %q", line)
+	}
+
+	parsed, err := notation.Parse(strings.NewReader(text.String()))
+	td.CmpNoError(t, err)
+	td.Cmp(t, parsed.Moves, g.Moves)
+	td.Cmp(t, parsed.Date, "")
+}
+
+func TestParseDefaults(t *testing.T) {
+	parsed, err := notation.Parse(strings.NewReader("1. 0,0 2. 3,-4\n"))
+	td.CmpNoError(t, err)
+
+	td.Cmp(t, parsed, &notation.Game{
+		Players:      []string{"?", "?"},
+		Variant:      notation.DefaultVariant,
+		StrikeLength: notation.DefaultStrikeLength,
+		Border:       notation.DefaultBorder,
+		Moves: []game.PlayerMove{
+			{Cell: geom.Offset{X: 0, Y: 0}, Player: game.P1},
+			{Cell: geom.Offset{X: 3, Y: -4}, Player: game.P2},
+		},
+	})
+}
+
+func TestParseAdjudicatedResult(t *testing.T) {
+	parsed, err := notation.Parse(strings.NewReader("[Result \"P1: win by resignation\"]\n\n1. 0,0 2. 1,0\n"))
+	td.CmpNoError(t, err)
+	td.Cmp(t, parsed.Result, game.GameResult{Outcome: game.OutcomeResignation, Winner: game.P1})
+
+	state, err := parsed.Replay()
+	td.CmpNoError(t, err)
+	td.Cmp(t, state.Result(), parsed.Result)
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []struct {
+		desc         string
+		text         string
+		line, column int
+		wantErr      error
+	}{
+		{"unclosed tag", `[Date "2022"`, 1, 13, nil},
+		{"unquoted tag", `[Date 2022]`, 1, 7, nil},
+		{"unknown variant", "\n  [Variant \"renju\"]", 2, 12, nil},
+		{"invalid strike", `[Strike "five"]`, 1, 9, nil},
+		{"single player", `[Players "Alice"]`, 1, 10, nil},
+		{"invalid result", `[Result "P1: draw by agreement"]`, 1, 9, nil},
+		{"missing turn number", "0,0", 1, 1, nil},
+		{"wrong turn number", "1. 0,0 3. 1,1", 1, 8, nil},
+		{"invalid coordinates", "1. 0;0", 1, 4, nil},
+		{"incomplete turn", "[Variant \"connect6\"]\n1. 0,0 2. 1,1 3. 2,2", 2, 15, nil},
+		{"too many stones", "1. 0,0 1,1", 1, 8, nil},
+		{"occupied cell", "1. 0,0\n2. 0,0", 2, 4, game.ErrCellOccupied},
+		{"unavailable cell", "[Border \"1\"]\n1. 0,0 2. 5,5", 2, 11, game.ErrCellUnavailable},
+		{"move after victory", "[Strike \"2\"]\n1. 0,0 2. 5,0 3. 1,0 4. 6,0", 2, 25, game.ErrGameOver},
+		{"tag after moves", "1. 0,0\n[Date \"2022\"]", 2, 1, nil},
+		{"wrong result", "[Strike \"2\"]\n[Result \"P2: win by strike\"]\n1. 0,0 2. 5,0 3. 1,0", 2, 9, nil},
+	}
+
+	for _, test := range cases {
+		t.Run(test.desc, func(t *testing.T) {
+			_, err := notation.Parse(strings.NewReader(test.text))
+
+			var notationErr *notation.Error
+			if !errors.As(err, &notationErr) {
+				t.Fatalf("got error [%v], want a *notation.Error", err)
+			}
+
+			td.Cmp(t, notationErr.Line, test.line, "line of [%v]", err)
+			td.Cmp(t, notationErr.Column, test.column, "column of [%v]", err)
+
+			if test.wantErr != nil && !errors.Is(err, test.wantErr) {
+				t.Errorf("got error [%v], want [%v]", err, test.wantErr)
+			}
+		})
+	}
+}
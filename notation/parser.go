@@ -0,0 +1,392 @@
+package notation
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/kitsunemikan/six-purrpurrs/game"
+	"github.com/kitsunemikan/six-purrpurrs/geom"
+)
+
+// Error is an error in the notation at the given 1-based line and column
+type Error struct {
+	Line, Column int
+	Err          error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("notation: line %d, column %d: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// token is a whitespace-separated word of a line
+type token struct {
+	text   string
+	column int
+}
+
+func tokenize(line []rune) []token {
+	var tokens []token
+
+	start := -1
+	for i := 0; i <= len(line); i++ {
+		if i < len(line) && !unicode.IsSpace(line[i]) {
+			if start < 0 {
+				start = i
+			}
+
+			continue
+		}
+
+		if start >= 0 {
+			tokens = append(tokens, token{text: string(line[start:i]), column: start + 1})
+			start = -1
+		}
+	}
+
+	return tokens
+}
+
+type parser struct {
+	game Game
+	line int
+
+	hasResult    bool
+	resultColumn int
+	resultLine   int
+
+	// state is created with the first move, once the header is over
+	state        *game.GameState
+	turn         int
+	stonesInTurn int
+}
+
+func (p *parser) errorf(column int, format string, args ...any) error {
+	return &Error{Line: p.line, Column: column, Err: fmt.Errorf(format, args...)}
+}
+
+func (p *parser) wrap(column int, err error) error {
+	return &Error{Line: p.line, Column: column, Err: err}
+}
+
+// parseTag parses a `[Name "value"]` line
+func (p *parser) parseTag(line []rune) error {
+	text := string(line)
+	indent := len(line) - len([]rune(strings.TrimLeftFunc(text, unicode.IsSpace)))
+	text = strings.TrimSpace(text)
+
+	if !strings.HasSuffix(text, "]") {
+		return p.errorf(len(line)+1, "tag isn't closed with ']'")
+	}
+
+	name, quoted, found := strings.Cut(text[1:len(text)-1], " ")
+	if !found || name == "" {
+		return p.errorf(indent+2, "tag has no name and value")
+	}
+
+	valueColumn := indent + 3 + len([]rune(name))
+
+	value, err := unquoteTag(quoted)
+	if err != nil {
+		return p.wrap(valueColumn, err)
+	}
+
+	switch name {
+	case "Players":
+		players := parsePlayers(value)
+		if len(players) < 2 {
+			return p.errorf(valueColumn, "at least 2 players are required, got %d", len(players))
+		}
+
+		p.game.Players = players
+
+	case "Date":
+		if value != unknownDate {
+			p.game.Date = value
+		}
+
+	case "Variant":
+		if _, known := Variants[value]; !known {
+			return p.errorf(valueColumn, "unknown variant %q", value)
+		}
+
+		p.game.Variant = value
+
+	case "Strike":
+		length, err := strconv.Atoi(value)
+		if err != nil || length < 1 {
+			return p.errorf(valueColumn, "invalid strike length %q", value)
+		}
+
+		p.game.StrikeLength = length
+
+	case "Border":
+		border, err := strconv.Atoi(value)
+		if err != nil || border < 0 {
+			return p.errorf(valueColumn, "invalid border %q", value)
+		}
+
+		p.game.Border = border
+
+	case "Result":
+		result, err := parseResult(value)
+		if err != nil {
+			return p.wrap(valueColumn, err)
+		}
+
+		p.game.Result = result
+		p.hasResult = true
+		p.resultLine = p.line
+		p.resultColumn = valueColumn
+	}
+
+	// Unknown tags are skipped, so that they may be used for comments
+	return nil
+}
+
+// parsePlayers splits the names at the commas, which aren't escaped with
+// a backslash, and trims the spaces around them, see formatPlayers
+func parsePlayers(value string) []string {
+	var players []string
+	var player strings.Builder
+	escaped := false
+	for _, ch := range value {
+		switch {
+		case escaped:
+			player.WriteRune(ch)
+			escaped = false
+		case ch == '\\':
+			escaped = true
+		case ch == ',':
+			players = append(players, strings.TrimSpace(player.String()))
+			player.Reset()
+		default:
+			player.WriteRune(ch)
+		}
+	}
+
+	return append(players, strings.TrimSpace(player.String()))
+}
+
+func unquoteTag(quoted string) (string, error) {
+	if len(quoted) < 2 || quoted[0] != '"' || quoted[len(quoted)-1] != '"' {
+		return "", errors.New("tag value isn't quoted")
+	}
+
+	var value strings.Builder
+	escaped := false
+	for _, ch := range quoted[1 : len(quoted)-1] {
+		switch {
+		case escaped:
+			value.WriteRune(ch)
+			escaped = false
+		case ch == '\\':
+			escaped = true
+		case ch == '"':
+			return "", errors.New("tag value has an unescaped quote")
+		default:
+			value.WriteRune(ch)
+		}
+	}
+
+	if escaped {
+		return "", errors.New("tag value ends with an escape")
+	}
+
+	return value.String(), nil
+}
+
+func (p *parser) startGame(column int) error {
+	options, err := p.game.Options()
+	if err != nil {
+		return p.wrap(column, err)
+	}
+
+	p.state = game.NewGame(options)
+	return nil
+}
+
+func (p *parser) stonesPerTurn() int {
+	return p.state.TurnPolicy().StonesLeftAt(p.state.MoveNumber() - p.stonesInTurn)
+}
+
+func (p *parser) parseMoves(line []rune) error {
+	for _, tok := range tokenize(line) {
+		if p.state == nil {
+			if err := p.startGame(tok.column); err != nil {
+				return err
+			}
+		}
+
+		if strings.HasSuffix(tok.text, ".") {
+			turn, err := strconv.Atoi(strings.TrimSuffix(tok.text, "."))
+			if err != nil {
+				return p.errorf(tok.column, "invalid turn number %q", tok.text)
+			}
+
+			if turn != p.turn+1 {
+				return p.errorf(tok.column, "got turn %d, want %d", turn, p.turn+1)
+			}
+
+			if p.turn > 0 && p.stonesInTurn < p.stonesPerTurn() {
+				return p.errorf(tok.column, "turn %d has %d stones, want %d", p.turn, p.stonesInTurn, p.stonesPerTurn())
+			}
+
+			p.turn = turn
+			p.stonesInTurn = 0
+			continue
+		}
+
+		if p.turn == 0 {
+			return p.errorf(tok.column, "expected a turn number, got %q", tok.text)
+		}
+
+		if p.stonesInTurn == p.stonesPerTurn() {
+			return p.errorf(tok.column, "turn %d has more than %d stones", p.turn, p.stonesPerTurn())
+		}
+
+		cell, err := parseCell(tok.text)
+		if err != nil {
+			return p.wrap(tok.column, err)
+		}
+
+		if err := p.state.Play(cell); err != nil {
+			return p.wrap(tok.column, err)
+		}
+
+		p.stonesInTurn++
+	}
+
+	return nil
+}
+
+func parseCell(text string) (geom.Offset, error) {
+	x, y, found := strings.Cut(text, ",")
+	if !found {
+		return geom.Offset{}, fmt.Errorf("expected x,y coordinates, got %q", text)
+	}
+
+	var cell geom.Offset
+	var errX, errY error
+	cell.X, errX = strconv.Atoi(x)
+	cell.Y, errY = strconv.Atoi(y)
+	if errX != nil || errY != nil {
+		return geom.Offset{}, fmt.Errorf("expected x,y coordinates, got %q", text)
+	}
+
+	return cell, nil
+}
+
+// checkResult makes sure the result in the header agrees with the moves
+func (p *parser) checkResult() error {
+	derived := p.state.Result()
+	if !p.hasResult {
+		p.game.Result = derived
+		return nil
+	}
+
+	resultErr := func(err error) error {
+		return &Error{Line: p.resultLine, Column: p.resultColumn, Err: err}
+	}
+
+	if derived.Over() {
+		if derived != p.game.Result {
+			return resultErr(fmt.Errorf("got result %q, but the moves end in %q", formatResult(p.game.Result), formatResult(derived)))
+		}
+
+		return nil
+	}
+
+	if p.game.Result.Over() {
+		if err := p.state.Adjudicate(p.game.Result); err != nil {
+			return resultErr(err)
+		}
+	}
+
+	return nil
+}
+
+// Parse reads a game and validates its moves against the rules
+func Parse(r io.Reader) (*Game, error) {
+	p := parser{
+		game: Game{
+			Players:      []string{"?", "?"},
+			Variant:      DefaultVariant,
+			StrikeLength: DefaultStrikeLength,
+			Border:       DefaultBorder,
+		},
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		p.line++
+		line := []rune(scanner.Text())
+
+		trimmed := strings.TrimSpace(string(line))
+		switch {
+		case trimmed == "":
+		case strings.HasPrefix(trimmed, "["):
+			if p.state != nil {
+				return nil, p.errorf(1, "tags must precede the moves")
+			}
+
+			if err := p.parseTag(line); err != nil {
+				return nil, err
+			}
+
+		default:
+			if err := p.parseMoves(line); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("notation: %w", err)
+	}
+
+	// A game without moves
+	if p.state == nil {
+		if err := p.startGame(1); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.checkResult(); err != nil {
+		return nil, err
+	}
+
+	p.game.Moves = p.state.MoveHistoryCopy()
+	return &p.game, nil
+}
+
+// Replay plays the moves of the game, which is then ready to be continued
+func (g *Game) Replay() (*game.GameState, error) {
+	options, err := g.Options()
+	if err != nil {
+		return nil, fmt.Errorf("notation: replay: %w", err)
+	}
+
+	state := game.NewGame(options)
+	for i, move := range g.Moves {
+		if err := state.TryMove(move.Cell, move.Player); err != nil {
+			return nil, fmt.Errorf("notation: replay: move %d: %w", i+1, err)
+		}
+	}
+
+	if !state.Over() && g.Result.Over() {
+		if err := state.Adjudicate(g.Result); err != nil {
+			return nil, fmt.Errorf("notation: replay: %w", err)
+		}
+	}
+
+	return state, nil
+}
@@ -0,0 +1,414 @@
+package notation
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kitsunemikan/six-purrpurrs/game"
+	"github.com/kitsunemikan/six-purrpurrs/geom"
+)
+
+// Position is a snapshot of a game on an expanding board, written in one line:
+//
+//	-1,-1:x1o/1x o 7 6
+//
+// The fields are separated by spaces:
+//   - the stones, see below, or '-' if there are none
+//   - the player to move
+//   - the border width
+//   - the strike length
+//   - the number of players, written only if there are more than 2
+//
+// The stones are written as the x,y coordinates of the top-left corner of their
+// bounding box, followed by ':' and the rows of the box separated by '/'.
+// Players are denoted by the letters of PlayerLetters, and a number denotes a run
+// of empty cells. The empty cells at the end of a row are omitted
+type Position struct {
+	Stones       map[geom.Offset]game.PlayerID
+	ToMove       game.PlayerID
+	Border       int
+	StrikeLength int
+
+	// Players is the number of players, or 0 to infer it, see PlayerCount
+	Players int
+}
+
+// PlayerLetters denote players P1 to P4 in positions, so games
+// of more players have no position
+const PlayerLetters = "xoav"
+
+const noStones = "-"
+
+// unknownPlayer is written in place of the players without a letter
+const unknownPlayer = '?'
+
+func playerLetter(player game.PlayerID) byte {
+	if !player.IsValid(len(PlayerLetters)) {
+		return unknownPlayer
+	}
+
+	return PlayerLetters[player]
+}
+
+func letterPlayer(letter rune) (game.PlayerID, bool) {
+	index := strings.IndexRune(PlayerLetters, letter)
+	return game.PlayerID(index), index >= 0
+}
+
+// PositionOf returns the current position of the game.
+// Fails for games of more players than there are PlayerLetters
+func PositionOf(g *game.GameState) (Position, error) {
+	if g.PlayerCount() > len(PlayerLetters) {
+		return Position{}, fmt.Errorf("position of game: %d players, up to %d are supported", g.PlayerCount(), len(PlayerLetters))
+	}
+
+	return Position{
+		Stones:       stonesOf(g.Board),
+		ToMove:       g.PlayerToMove(),
+		Border:       g.Board.BorderWidth(),
+		StrikeLength: g.VictoryChecker().StrikeLength(),
+		Players:      g.PlayerCount(),
+	}, nil
+}
+
+// PositionOfBoard returns the position of the board, which has no notion
+// of the player to move nor of the strike length. Fails like PositionOf
+func PositionOfBoard(bs *game.BoardState, toMove game.PlayerID, strikeLength int) (Position, error) {
+	if bs.PlayerCount() > len(PlayerLetters) {
+		return Position{}, fmt.Errorf("position of board: %d players, up to %d are supported", bs.PlayerCount(), len(PlayerLetters))
+	}
+
+	return Position{
+		Stones:       stonesOf(bs),
+		ToMove:       toMove,
+		Border:       bs.BorderWidth(),
+		StrikeLength: strikeLength,
+		Players:      bs.PlayerCount(),
+	}, nil
+}
+
+func stonesOf(bs *game.BoardState) map[geom.Offset]game.PlayerID {
+	stones := make(map[geom.Offset]game.PlayerID, bs.MoveCount())
+	for player := 0; player < bs.PlayerCount(); player++ {
+		for _, cell := range bs.PlayerCellList(game.PlayerID(player)) {
+			stones[cell] = game.PlayerID(player)
+		}
+	}
+
+	return stones
+}
+
+// PlayerCount returns the number of players in the position. Unless it's set, it's
+// inferred from the highest player present, and is at least 2
+func (p Position) PlayerCount() int {
+	if p.Players != 0 {
+		return p.Players
+	}
+
+	count := 2
+	if int(p.ToMove) >= count {
+		count = int(p.ToMove) + 1
+	}
+
+	for _, player := range p.Stones {
+		if int(player) >= count {
+			count = int(player) + 1
+		}
+	}
+
+	return count
+}
+
+// String writes the position in one line. Players without a letter
+// are written as '?', which ParsePosition doesn't accept
+func (p Position) String() string {
+	var text strings.Builder
+	text.WriteString(p.stonesString())
+	text.WriteByte(' ')
+	text.WriteByte(playerLetter(p.ToMove))
+	text.WriteString(fmt.Sprintf(" %d %d", p.Border, p.StrikeLength))
+
+	if p.Players > 2 {
+		text.WriteString(fmt.Sprintf(" %d", p.Players))
+	}
+
+	return text.String()
+}
+
+func (p Position) stonesString() string {
+	if len(p.Stones) == 0 {
+		return noStones
+	}
+
+	var bound geom.Rect
+	first := true
+	for cell := range p.Stones {
+		if first {
+			bound = geom.Rect{X: cell.X, Y: cell.Y, W: 1, H: 1}
+			first = false
+			continue
+		}
+
+		bound = bound.GrowToContainOffset(cell)
+	}
+
+	var text strings.Builder
+	text.WriteString(formatCell(bound.TopLeft()))
+	text.WriteByte(':')
+
+	for y := bound.Y; y < bound.Y+bound.H; y++ {
+		if y > bound.Y {
+			text.WriteByte('/')
+		}
+
+		empty := 0
+		for x := bound.X; x < bound.X+bound.W; x++ {
+			player, occupied := p.Stones[geom.Offset{X: x, Y: y}]
+			if !occupied {
+				empty++
+				continue
+			}
+
+			if empty > 0 {
+				text.WriteString(strconv.Itoa(empty))
+				empty = 0
+			}
+
+			text.WriteByte(playerLetter(player))
+		}
+	}
+
+	return text.String()
+}
+
+// ParsePosition reads a position. Errors are *Error with line 1
+func ParsePosition(text string) (Position, error) {
+	positionErr := func(column int, err error) error {
+		return &Error{Line: 1, Column: column, Err: err}
+	}
+
+	fields := tokenize([]rune(text))
+	if len(fields) != 4 && len(fields) != 5 {
+		return Position{}, positionErr(1, fmt.Errorf("got %d fields, want 4 or 5: stones, player to move, border, strike length and optionally player count", len(fields)))
+	}
+
+	stones, err := parseStones(fields[0].text)
+	if err != nil {
+		var stonesErr *Error
+		if errors.As(err, &stonesErr) {
+			return Position{}, positionErr(fields[0].column+stonesErr.Column-1, stonesErr.Err)
+		}
+
+		return Position{}, positionErr(fields[0].column, err)
+	}
+
+	toMove, ok := letterPlayer([]rune(fields[1].text)[0])
+	if !ok || len(fields[1].text) != 1 {
+		return Position{}, positionErr(fields[1].column, fmt.Errorf("invalid player to move %q", fields[1].text))
+	}
+
+	border, err := strconv.Atoi(fields[2].text)
+	if err != nil || border < 0 {
+		return Position{}, positionErr(fields[2].column, fmt.Errorf("invalid border %q", fields[2].text))
+	}
+
+	strikeLength, err := strconv.Atoi(fields[3].text)
+	if err != nil || strikeLength < 1 {
+		return Position{}, positionErr(fields[3].column, fmt.Errorf("invalid strike length %q", fields[3].text))
+	}
+
+	position := Position{
+		Stones:       stones,
+		ToMove:       toMove,
+		Border:       border,
+		StrikeLength: strikeLength,
+	}
+
+	if len(fields) == 5 {
+		// The players present must fit into the count
+		inferred := position.PlayerCount()
+
+		players, err := strconv.Atoi(fields[4].text)
+		if err != nil || players < inferred || players > len(PlayerLetters) {
+			return Position{}, positionErr(fields[4].column, fmt.Errorf("invalid player count %q, want %d to %d", fields[4].text, inferred, len(PlayerLetters)))
+		}
+
+		position.Players = players
+	}
+
+	return position, nil
+}
+
+// parseStones returns *Error with the column relative to the beginning of the text
+func parseStones(text string) (map[geom.Offset]game.PlayerID, error) {
+	stones := make(map[geom.Offset]game.PlayerID)
+	if text == noStones {
+		return stones, nil
+	}
+
+	corner, rows, found := strings.Cut(text, ":")
+	if !found {
+		return nil, &Error{Column: 1, Err: fmt.Errorf("expected x,y:rows, got %q", text)}
+	}
+
+	origin, err := parseCell(corner)
+	if err != nil {
+		return nil, &Error{Column: 1, Err: err}
+	}
+
+	cell := origin
+	column := len([]rune(corner)) + 1
+	runLength := 0
+	for _, ch := range rows {
+		column++
+
+		switch {
+		case ch >= '0' && ch <= '9':
+			runLength = 10*runLength + int(ch-'0')
+			continue
+
+		case ch == '/':
+			cell = geom.Offset{X: origin.X, Y: cell.Y + 1}
+
+		default:
+			player, ok := letterPlayer(ch)
+			if !ok {
+				return nil, &Error{Column: column, Err: fmt.Errorf("unknown cell %q", ch)}
+			}
+
+			cell = cell.AddXY(runLength, 0)
+			stones[cell] = player
+			cell = cell.AddXY(1, 0)
+		}
+
+		runLength = 0
+	}
+
+	return stones, nil
+}
+
+// moveOrder orders the stones, so that the players place them according to
+// the turn policy, and the given player is to move after the last of them
+func (p Position) moveOrder(turns game.TurnPolicy, playerCount int) ([]game.PlayerMove, error) {
+	byPlayer := make([][]geom.Offset, playerCount)
+	for cell, player := range p.Stones {
+		byPlayer[player] = append(byPlayer[player], cell)
+	}
+
+	// Sorted for the history to be the same for the same position
+	for _, cells := range byPlayer {
+		sort.Slice(cells, func(i, j int) bool {
+			if cells[i].Y != cells[j].Y {
+				return cells[i].Y < cells[j].Y
+			}
+
+			return cells[i].X < cells[j].X
+		})
+	}
+
+	moves := make([]game.PlayerMove, 0, len(p.Stones))
+	for moveNumber := 1; moveNumber <= len(p.Stones); moveNumber++ {
+		player := turns.PlayerAt(moveNumber, playerCount)
+		if len(byPlayer[player]) == 0 {
+			return nil, fmt.Errorf("position: %v has too few stones for the turn order", player)
+		}
+
+		moves = append(moves, game.PlayerMove{Cell: byPlayer[player][0], Player: player})
+		byPlayer[player] = byPlayer[player][1:]
+	}
+
+	if next := turns.PlayerAt(len(p.Stones)+1, playerCount); next != p.ToMove {
+		return nil, fmt.Errorf("position: %v is to move after %d stones, not %v", next, len(p.Stones), p.ToMove)
+	}
+
+	return moves, nil
+}
+
+// Board places the stones on a new board
+func (p Position) Board() (*game.BoardState, error) {
+	moves, err := p.moveOrder(game.SingleStoneTurns, p.PlayerCount())
+	if err != nil {
+		return nil, err
+	}
+
+	bs := game.NewBoardState(p.Border, p.PlayerCount())
+	for _, move := range moves {
+		bs.MarkCell(move.Cell, move.Player)
+	}
+
+	return bs, nil
+}
+
+// Place puts the stones on the board of a game without moves, in the order
+// of its turn policy. The player to move must agree with the number of stones
+// of each player, since GameState derives it from the move history
+func (p Position) Place(g *game.GameState) error {
+	if g.MoveNumber() != 1 {
+		return fmt.Errorf("position: place: the game has %d moves already", g.MoveNumber()-1)
+	}
+
+	if p.Players != 0 && p.Players != g.PlayerCount() {
+		return fmt.Errorf("position: place: the position of %d players doesn't fit a game of %d players", p.Players, g.PlayerCount())
+	}
+
+	if !p.ToMove.IsValid(g.PlayerCount()) {
+		return fmt.Errorf("position: place: invalid player to move %v for a game of %d players", p.ToMove, g.PlayerCount())
+	}
+
+	// Stones may be placed anywhere on an expanding board, but only on unoccupied
+	// cells of a fixed-size one, which may also wrap a pair of them into the same cell
+	normalized := make(map[geom.Offset]struct{}, len(p.Stones))
+	for cell, player := range p.Stones {
+		if !player.IsValid(g.PlayerCount()) {
+			return fmt.Errorf("position: place at %v: invalid player %v for a game of %d players", cell, player, g.PlayerCount())
+		}
+
+		state := g.Board.Cell(cell)
+		if state == game.CellBlocked || g.Board.Bounded() && state == game.CellUnavailable {
+			return fmt.Errorf("position: place at %v: %w", cell, game.ErrCellUnavailable)
+		}
+
+		if _, wrapped := normalized[g.Board.Normalize(cell)]; wrapped {
+			return fmt.Errorf("position: place at %v: %w", cell, game.ErrCellOccupied)
+		}
+
+		normalized[g.Board.Normalize(cell)] = struct{}{}
+	}
+
+	moves, err := p.moveOrder(g.TurnPolicy(), g.PlayerCount())
+	if err != nil {
+		return err
+	}
+
+	for _, move := range moves {
+		if err := g.MarkCell(move.Cell, move.Player); err != nil {
+			return fmt.Errorf("position: place: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// NewGame starts a game from the position with free rules and the given turn policy.
+// SingleStoneTurns are used, if turns is nil
+func (p Position) NewGame(turns game.TurnPolicy) (*game.GameState, error) {
+	if turns == nil {
+		turns = game.SingleStoneTurns
+	}
+
+	g := game.NewGame(game.GameOptions{
+		Border:      p.Border,
+		PlayerCount: p.PlayerCount(),
+		Turns:       turns,
+		Victory:     &game.EightDirStrikeVictoryChecker{VictoryLength: p.StrikeLength},
+	})
+
+	if err := p.Place(g); err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
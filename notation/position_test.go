@@ -0,0 +1,217 @@
+package notation_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"github.com/kitsunemikan/six-purrpurrs/game"
+	"github.com/kitsunemikan/six-purrpurrs/geom"
+	"github.com/kitsunemikan/six-purrpurrs/notation"
+)
+
+func TestPositionString(t *testing.T) {
+	position := notation.Position{
+		Stones: map[geom.Offset]game.PlayerID{
+			{X: -1, Y: -1}: game.P1,
+			{X: 1, Y: -1}:  game.P2,
+			{X: 0, Y: 0}:   game.P1,
+			{X: 12, Y: 1}:  game.P2,
+		},
+		ToMove:       game.P1,
+		Border:       7,
+		StrikeLength: 6,
+	}
+
+	text := position.String()
+	td.Cmp(t, text, "-1,-1:x1o/1x/13o x 7 6")
+
+	parsed, err := notation.ParsePosition(text)
+	td.CmpNoError(t, err)
+	td.Cmp(t, parsed, position)
+
+	empty, err := notation.ParsePosition("- o 3 5")
+	td.CmpNoError(t, err)
+	td.Cmp(t, empty, notation.Position{Stones: map[geom.Offset]game.PlayerID{}, ToMove: game.P2, Border: 3, StrikeLength: 5})
+	td.Cmp(t, empty.String(), "- o 3 5")
+}
+
+func TestPositionGameRoundTrip(t *testing.T) {
+	g := game.NewGame(game.GameOptions{
+		Border:      3,
+		PlayerCount: 2,
+		Turns:       game.Connect6Turns,
+		Victory:     &game.EightDirStrikeVictoryChecker{VictoryLength: 4},
+	})
+
+	for _, cell := range []geom.Offset{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: -2, Y: 2}} {
+		td.CmpNoError(t, g.Play(cell))
+	}
+
+	position, err := notation.PositionOf(g)
+	td.CmpNoError(t, err)
+	td.Cmp(t, position.ToMove, game.P1)
+	td.Cmp(t, position.String(), "-2,0:2xo/3o/x x 3 4")
+
+	restored, err := position.NewGame(game.Connect6Turns)
+	td.CmpNoError(t, err)
+	td.Cmp(t, restored.PlayerToMove(), game.P1)
+	td.Cmp(t, restored.MoveNumber(), g.MoveNumber())
+	td.Cmp(t, restored.Board.BorderWidth(), 3)
+	td.Cmp(t, restored.VictoryChecker().StrikeLength(), 4)
+	restoredPosition, err := notation.PositionOf(restored)
+	td.CmpNoError(t, err)
+	td.Cmp(t, restoredPosition, position)
+
+	td.CmpNoError(t, restored.Play(geom.Offset{X: 2, Y: 0}))
+
+	board, err := notation.Position{
+		Stones:       map[geom.Offset]game.PlayerID{{X: 0, Y: 0}: game.P1},
+		ToMove:       game.P2,
+		Border:       2,
+		StrikeLength: 6,
+	}.Board()
+	td.CmpNoError(t, err)
+	td.Cmp(t, board.Cell(geom.Offset{X: 0, Y: 0}), game.CellP1)
+	boardPosition, err := notation.PositionOfBoard(board, game.P2, 6)
+	td.CmpNoError(t, err)
+	td.Cmp(t, boardPosition.String(), "0,0:x o 2 6")
+}
+
+func TestPositionPlayerCountRoundTrip(t *testing.T) {
+	// Neither the stones nor the player to move tell that there are 3 players
+	g := game.NewGame(game.GameOptions{
+		Border:      7,
+		PlayerCount: 3,
+		Victory:     &game.EightDirStrikeVictoryChecker{VictoryLength: 6},
+	})
+
+	position, err := notation.PositionOf(g)
+	td.CmpNoError(t, err)
+	td.Cmp(t, position.String(), "- x 7 6 3")
+
+	parsed, err := notation.ParsePosition(position.String())
+	td.CmpNoError(t, err)
+	td.Cmp(t, parsed, position)
+	td.Cmp(t, parsed.PlayerCount(), 3)
+
+	restored, err := parsed.NewGame(nil)
+	td.CmpNoError(t, err)
+	td.Cmp(t, restored.PlayerCount(), 3)
+
+	td.CmpError(t, parsed.Place(game.NewGame(game.GameOptions{
+		Border:      7,
+		PlayerCount: 2,
+		Victory:     &game.EightDirStrikeVictoryChecker{VictoryLength: 6},
+	})))
+}
+
+func TestPositionTooManyPlayers(t *testing.T) {
+	g := game.NewGame(game.GameOptions{
+		Border:      2,
+		PlayerCount: 5,
+		Victory:     &game.EightDirStrikeVictoryChecker{VictoryLength: 4},
+	})
+
+	_, err := notation.PositionOf(g)
+	td.CmpError(t, err)
+
+	_, err = notation.PositionOfBoard(g.Board, game.P1, 4)
+	td.CmpError(t, err)
+
+	// Players without a letter don't crash the formatting, but can't be read back
+	text := notation.Position{
+		Stones:       map[geom.Offset]game.PlayerID{{X: 0, Y: 0}: game.PlayerID(4)},
+		ToMove:       game.P1,
+		Border:       2,
+		StrikeLength: 4,
+	}.String()
+	td.Cmp(t, text, "0,0:? x 2 4")
+
+	_, err = notation.ParsePosition(text)
+	td.CmpError(t, err)
+}
+
+func TestPositionNewGameTurnOrder(t *testing.T) {
+	// P2 to move after P1 has placed two stones
+	position, err := notation.ParsePosition("0,0:xx o 7 6")
+	td.CmpNoError(t, err)
+
+	_, err = position.NewGame(game.SingleStoneTurns)
+	td.CmpError(t, err)
+
+	// In Connect6 P1 has a single stone in the first turn
+	position, err = notation.ParsePosition("0,0:xo x 7 6")
+	td.CmpNoError(t, err)
+
+	_, err = position.NewGame(game.Connect6Turns)
+	td.CmpError(t, err)
+
+	_, err = position.NewGame(nil)
+	td.CmpNoError(t, err)
+}
+
+func TestParsePositionErrors(t *testing.T) {
+	cases := []struct {
+		desc   string
+		text   string
+		column int
+	}{
+		{"too few fields", "0,0:x o 7", 1},
+		{"no corner", "x o 7 6", 1},
+		{"invalid corner", "0;0:x o 7 6", 1},
+		{"unknown cell", "0,0:x1z o 7 6", 7},
+		{"unknown player", "0,0:x z 7 6", 7},
+		{"invalid border", "0,0:x o -1 6", 9},
+		{"invalid strike", " 0,0:x o 7 0", 12},
+		{"too many fields", "0,0:x o 7 6 3 1", 1},
+		{"too few players", "0,0:xoa x 7 6 2", 15},
+		{"too many players", "0,0:x o 7 6 5", 13},
+	}
+
+	for _, test := range cases {
+		t.Run(test.desc, func(t *testing.T) {
+			_, err := notation.ParsePosition(test.text)
+
+			var notationErr *notation.Error
+			if !errors.As(err, &notationErr) {
+				t.Fatalf("got error [%v], want a *notation.Error", err)
+			}
+
+			td.Cmp(t, notationErr.Line, 1, "line of [%v]", err)
+			td.Cmp(t, notationErr.Column, test.column, "column of [%v]", err)
+		})
+	}
+}
+
+func TestPositionPlace(t *testing.T) {
+	newGame := func() *game.GameState {
+		return game.NewGame(game.GameOptions{
+			Border:      2,
+			BoardSize:   geom.Offset{X: 3, Y: 3},
+			PlayerCount: 2,
+			Turns:       game.SingleStoneTurns,
+			Rules:       game.RenjuRules{Restricted: game.P1},
+			Victory:     &game.EightDirStrikeVictoryChecker{VictoryLength: 3},
+		})
+	}
+
+	position, err := notation.ParsePosition("0,0:xo/x o 2 3")
+	td.CmpNoError(t, err)
+
+	g := newGame()
+	td.CmpNoError(t, position.Place(g))
+	td.Cmp(t, g.PlayerToMove(), game.P2)
+	td.Cmp(t, g.Board.Cell(geom.Offset{X: 0, Y: 1}), game.CellP1)
+
+	td.CmpError(t, position.Place(g), "the game has moves already")
+
+	outside, err := notation.ParsePosition("0,0:2x/o/3x o 2 3")
+	td.CmpNoError(t, err)
+
+	err = outside.Place(newGame())
+	if !errors.Is(err, game.ErrCellUnavailable) {
+		t.Errorf("got error [%v], want [%v]", err, game.ErrCellUnavailable)
+	}
+}
@@ -0,0 +1,114 @@
+package notation
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/kitsunemikan/six-purrpurrs/geom"
+)
+
+// lineWidth is the width after which the move list is wrapped
+const lineWidth = 80
+
+// unknownDate is written in place of an empty date, like in PGN
+const unknownDate = "????.??.??"
+
+func quoteTag(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return `"` + value + `"`
+}
+
+// formatPlayers joins the names with commas. The commas and backslashes
+// in the names are escaped with a backslash, see parsePlayers
+func formatPlayers(players []string) string {
+	escaped := make([]string, len(players))
+	for i, player := range players {
+		player = strings.ReplaceAll(player, `\`, `\\`)
+		escaped[i] = strings.ReplaceAll(player, ",", `\,`)
+	}
+
+	return strings.Join(escaped, ", ")
+}
+
+func formatCell(cell geom.Offset) string {
+	return strconv.Itoa(cell.X) + "," + strconv.Itoa(cell.Y)
+}
+
+// Write writes the header and the moves of the game
+func Write(w io.Writer, g *Game) error {
+	turns, known := Variants[g.Variant]
+	if !known {
+		return fmt.Errorf("notation: write: unknown variant %q", g.Variant)
+	}
+
+	date := g.Date
+	if date == "" {
+		date = unknownDate
+	}
+
+	out := bufio.NewWriter(w)
+
+	fmt.Fprintf(out, "[Players %s]\n", quoteTag(formatPlayers(g.Players)))
+	fmt.Fprintf(out, "[Date %s]\n", quoteTag(date))
+	fmt.Fprintf(out, "[Variant %s]\n", quoteTag(g.Variant))
+	fmt.Fprintf(out, "[Strike %s]\n", quoteTag(strconv.Itoa(g.StrikeLength)))
+	fmt.Fprintf(out, "[Border %s]\n", quoteTag(strconv.Itoa(g.Border)))
+	fmt.Fprintf(out, "[Result %s]\n", quoteTag(formatResult(g.Result)))
+	out.WriteByte('\n')
+
+	var line strings.Builder
+	turn := 0
+	for i, move := range g.Moves {
+		moveNumber := i + 1
+
+		// Turns are written as a whole, unless they don't fit into a line at all
+		var word string
+		if moveNumber == 1 || turns.StonesLeftAt(moveNumber-1) == 1 {
+			turn++
+			word = strconv.Itoa(turn) + ". "
+
+			if line.Len() > 0 && line.Len()+turnWidth(g, i, turn) > lineWidth {
+				out.WriteString(line.String())
+				out.WriteByte('\n')
+				line.Reset()
+			}
+
+			if line.Len() > 0 {
+				word = " " + word
+			}
+		} else {
+			word = " "
+		}
+
+		line.WriteString(word)
+		line.WriteString(formatCell(move.Cell))
+	}
+
+	if line.Len() > 0 {
+		out.WriteString(line.String())
+		out.WriteByte('\n')
+	}
+
+	return out.Flush()
+}
+
+// turnWidth returns the width of the turn starting at the move with the given index
+func turnWidth(g *Game, index, turn int) int {
+	turns := Variants[g.Variant]
+
+	width := len(strconv.Itoa(turn)) + 2
+	for i := index; i < len(g.Moves); i++ {
+		width += len(formatCell(g.Moves[i].Cell))
+		if turns.StonesLeftAt(i+1) == 1 {
+			break
+		}
+
+		width++
+	}
+
+	return width
+}